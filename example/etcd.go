@@ -25,18 +25,20 @@ func main() {
 		DialTimeout: 5 * time.Second,
 	}
 	// 创建 ETCD 连接池
-	etcdPool := connection_pool.NewConnectionPool(connection_pool.EtcdMode(etcdCfg, cfg))
-	defer etcdPool.Close()
+	p, err := connection_pool.NewEtcdPool(etcdCfg, cfg)
+	if err != nil {
+		log.Fatal("Failed to create ETCD connection pool:", err)
+	}
+	etcdPool := connection_pool.NewPool(p)
+	defer etcdPool.Close(context.Background())
 
 	// 从 ETCD 连接池获取连接
-	etcdConn, err := etcdPool.GetConnection()
+	etcdConn, err := etcdPool.GetConnection(context.Background())
 	if err != nil {
 		log.Fatal("Failed to get ETCD connection:", err)
 	}
-
-	// 获取后需要先转回连接对象
-	etcdClient := etcdConn.(*clientv3.Client)
-	defer etcdPool.ReleaseConnection(etcdConn)
+	defer etcdConn.Release()
+	etcdClient := etcdConn.Conn()
 
 	// 执行 ETCD 操作
 	_, err = etcdClient.Put(context.Background(), "aaa", "aaa")
@@ -1,7 +1,7 @@
 package main
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
 	"github.com/practice/connection-pool/pkg/pool/config"
 	"github.com/practice/connection-pool/pkg/pool/connection_pool"
@@ -19,21 +19,23 @@ func main() {
 	}
 
 	//// 创建 MySQL 连接池
-	mysqlPool := connection_pool.NewConnectionPool(connection_pool.MysqlMode("mysql", "root:1234567@tcp(127.0.0.1:3306)/testdb", cfg))
-	defer mysqlPool.Close()
+	p, err := connection_pool.NewMysqlPool("mysql", "root:1234567@tcp(127.0.0.1:3306)/testdb", cfg)
+	if err != nil {
+		log.Fatal("Failed to create MySQL connection pool:", err)
+	}
+	mysqlPool := connection_pool.NewPool(p)
+	defer mysqlPool.Close(context.Background())
 
 	// 从 MySQL 连接池获取连接
-	mysqlConn, err := mysqlPool.GetConnection()
+	mysqlHandle, err := mysqlPool.GetConnection(context.Background())
 	if err != nil {
 		log.Fatal("Failed to get MySQL connection:", err)
 	}
-
-	// 获取后需要先转回连接对象
-	mysqlDB := mysqlConn.(*sql.DB)
-	defer mysqlPool.ReleaseConnection(mysqlConn)
+	defer mysqlHandle.Release()
+	mysqlConn := mysqlHandle.Conn()
 
 	// 执行数据库查询操作
-	rows, err := mysqlDB.Query("SELECT * FROM example")
+	rows, err := mysqlConn.QueryContext(context.Background(), "SELECT * FROM example")
 	if err != nil {
 		log.Fatal("Failed to execute MySQL query:", err)
 	}
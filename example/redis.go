@@ -3,7 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
-	redis2 "github.com/go-redis/redis/v8"
+	"github.com/go-redis/redis/v8"
 	"github.com/practice/connection-pool/pkg/pool/config"
 	"github.com/practice/connection-pool/pkg/pool/connection_pool"
 	"log"
@@ -20,29 +20,26 @@ func main() {
 	}
 
 	// 创建 Redis 连接池
-	redisPool := connection_pool.NewConnectionPool(connection_pool.RedisMode("127.0.0.1:6379", "", cfg))
-	defer redisPool.Close()
-
-	// 从 Redis 连接池获取连接
-	redisConn, err := redisPool.GetConnection()
+	p, err := connection_pool.NewRedisPool("127.0.0.1:6379", "", cfg)
 	if err != nil {
-		log.Fatal("Failed to get Redis connection:", err)
+		log.Fatal("Failed to create Redis connection pool:", err)
 	}
+	redisPool := connection_pool.NewPool(p)
+	defer redisPool.Close(context.Background())
 
-	// 获取后需要先转回连接对象
-	redisClient := redisConn.(*redis2.Client)
-	defer redisPool.ReleaseConnection(redisConn)
-
-	// 执行 Redis 操作
-	err = redisClient.Set(context.Background(), "my-key", "my-value", 0).Err()
+	// 借出连接执行 Redis 操作，结束后自动归还，连接故障时销毁连接并换一个连接重试
+	err = redisPool.DoWithRetry(context.Background(), 3, func(redisClient *redis.Client) error {
+		if err := redisClient.Set(context.Background(), "my-key", "my-value", 0).Err(); err != nil {
+			return err
+		}
+		cc := redisClient.Get(context.Background(), "my-key")
+		if cc.Err() != nil {
+			return cc.Err()
+		}
+		fmt.Println(cc.String())
+		return nil
+	})
 	if err != nil {
-		log.Fatal("Failed to set Redis key:", err)
-	}
-
-	cc := redisClient.Get(context.Background(), "my-key")
-	if cc.Err() != nil {
-		fmt.Println("err: ", err)
-		return
+		log.Fatal("Failed to execute Redis commands:", err)
 	}
-	fmt.Println(cc.String())
 }
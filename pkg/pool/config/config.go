@@ -1,17 +1,136 @@
 package config
 
-import "time"
+import (
+	"context"
+	"fmt"
+	"github.com/practice/connection-pool/pkg/pool/errs"
+	"time"
+)
 
 // ConnectionConfig 连接池通用配置
 type ConnectionConfig struct {
 	// MaxConnections 最大连接数量
 	MaxConnections int
+	// MinIdle 最少空闲连接数量，连接池启动时预先创建，并在后台补充，
+	// 空闲连接不足时按需增长到 MaxConnections
+	MinIdle int
 	// Timeout 获取连接时的超时时间
 	Timeout time.Duration
+	// ReservedConnections 为高优先级调用方预留的连接数，其余调用方最多同时借出
+	// MaxConnections - ReservedConnections 个连接，为 0 时不预留
+	ReservedConnections int
+	// MaxWaiters 最多同时等待空闲连接的调用方数量，超过后 GetConnection 立即返回 ErrPoolExhausted，为 0 时不限制
+	MaxWaiters int
 	// MaxIdleTime 连接最长空闲时间
 	MaxIdleTime time.Duration
-	// HealthCheckInterval 心跳检查时间
+	// MaxLifetime 连接最长存活时间，超过后在归还或定期清理时关闭，借出期间不会关闭，为 0 时不限制
+	MaxLifetime time.Duration
+	// LifetimeJitter 存活时间随机抖动，每个连接的存活时间在 [MaxLifetime-LifetimeJitter, MaxLifetime]
+	// 之间随机取值，避免同时创建的连接同时过期
+	LifetimeJitter time.Duration
+	// HealthCheckInterval 心跳检查时间，同时用于补充连接与泄漏检测
 	HealthCheckInterval time.Duration
+	// TestOnBorrow 借出连接前通过工厂 Validate 检查，检查失败时关闭该连接并换一个连接
+	TestOnBorrow bool
+	// TestOnReturn 归还连接时通过工厂 Validate 检查，检查失败时关闭该连接
+	TestOnReturn bool
+	// DisableTestWhileIdle 关闭按 HealthCheckInterval 定时检查空闲连接(TestWhileIdle)，
+	// 默认开启，检查失败时关闭该连接
+	DisableTestWhileIdle bool
+	// SkipValidationWithin 连接在该时间内使用过(借出时为最近一次归还或创建，归还时为借出)时跳过上述检查，
+	// 为 0 时每次都检查
+	SkipValidationWithin time.Duration
 	// CleanupInterval 清理空闲连接触发时间
 	CleanupInterval time.Duration
+	// DialBackoff 创建连接失败后的初始退避时间，连续失败时每次翻倍，退避期间不创建连接，
+	// 避免后端故障时大量实例同时重连，为 0 时不退避，按 HealthCheckInterval 重试
+	DialBackoff time.Duration
+	// DialBackoffMax 最长退避时间，开启 DialBackoff 时不能小于 DialBackoff
+	DialBackoffMax time.Duration
+	// DialBackoffJitter 退避时间随机缩短的最大比例，取值 [0, 1]，避免多个实例同时重连
+	DialBackoffJitter float64
+	// Lazy 懒加载模式，创建连接池时后端不可用不会报错，
+	// 连接池以降级状态启动，由定时任务在后端恢复后补充连接，此时 MinIdle 至少按 1 计算
+	Lazy bool
+	// Tracer 链路追踪，为 nil 时不记录，OpenTelemetry 实现见 tracing 包
+	Tracer Tracer
+	// Hooks 连接生命周期钩子
+	Hooks Hooks
+	// LeakThreshold 泄漏检测阈值，连接借出超过该时间未归还时通过 OnLeak 钩子或 log 报告，
+	// 开启后获取连接时会记录调用栈，按 HealthCheckInterval 检查，为 0 时不检测
+	LeakThreshold time.Duration
+	// LeakReclaim 强制回收泄漏的连接，回收后连接被关闭，持有方继续使用会出错，需开启 LeakThreshold
+	LeakReclaim bool
+	// PanicOnInvalidRelease 重复归还或归还不属于该连接池的连接时 panic，默认返回错误
+	PanicOnInvalidRelease bool
+	// DebugRelease 调试模式，记录每次归还的调用栈，重复归还时错误信息中附带第一次归还的调用栈
+	DebugRelease bool
+}
+
+// Tracer 连接池链路追踪接口，实现需保证各方法可并发调用
+type Tracer interface {
+	// StartAcquire 开始一次获取连接，返回的 ctx 会在归还该连接时传给 Release，
+	// 获取结束时调用 end，wait 为等待空闲连接的时间
+	StartAcquire(ctx context.Context, pool string) (_ context.Context, end func(wait time.Duration, err error))
+	// Release 记录一次连接归还，ctx 为获取该连接时 StartAcquire 返回的 ctx，borrowed 为借出时长
+	Release(ctx context.Context, pool string, borrowed time.Duration)
+	// StartHealthCheck 开始一轮健康检查，返回的 ctx 用于检查每个连接，
+	// 结束时调用 end，checked 与 failed 分别为检查与失败的连接数
+	StartHealthCheck(ctx context.Context, pool string) (_ context.Context, end func(checked, failed int))
+}
+
+// Validate 校验配置是否合法，不合法时返回包装了 errs.ErrInvalidConfig 的错误
+func (c *ConnectionConfig) Validate() error {
+	if c == nil {
+		return fmt.Errorf("%w: config is nil", errs.ErrInvalidConfig)
+	}
+	if c.MaxConnections <= 0 {
+		return fmt.Errorf("%w: MaxConnections must be positive, got %d", errs.ErrInvalidConfig, c.MaxConnections)
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("%w: Timeout must be positive, got %s", errs.ErrInvalidConfig, c.Timeout)
+	}
+	if c.MaxIdleTime <= 0 {
+		return fmt.Errorf("%w: MaxIdleTime must be positive, got %s", errs.ErrInvalidConfig, c.MaxIdleTime)
+	}
+	if c.HealthCheckInterval <= 0 {
+		return fmt.Errorf("%w: HealthCheckInterval must be positive, got %s", errs.ErrInvalidConfig, c.HealthCheckInterval)
+	}
+	if c.CleanupInterval <= 0 {
+		return fmt.Errorf("%w: CleanupInterval must be positive, got %s", errs.ErrInvalidConfig, c.CleanupInterval)
+	}
+	if c.MaxLifetime < 0 {
+		return fmt.Errorf("%w: MaxLifetime must not be negative, got %s", errs.ErrInvalidConfig, c.MaxLifetime)
+	}
+	if c.LifetimeJitter < 0 || c.LifetimeJitter > c.MaxLifetime {
+		return fmt.Errorf("%w: LifetimeJitter must be in [0, MaxLifetime], got %s", errs.ErrInvalidConfig, c.LifetimeJitter)
+	}
+	if c.SkipValidationWithin < 0 {
+		return fmt.Errorf("%w: SkipValidationWithin must not be negative, got %s", errs.ErrInvalidConfig, c.SkipValidationWithin)
+	}
+	if c.DialBackoff < 0 {
+		return fmt.Errorf("%w: DialBackoff must not be negative, got %s", errs.ErrInvalidConfig, c.DialBackoff)
+	}
+	if c.DialBackoff > 0 && c.DialBackoffMax < c.DialBackoff {
+		return fmt.Errorf("%w: DialBackoffMax must not be less than DialBackoff, got %s", errs.ErrInvalidConfig, c.DialBackoffMax)
+	}
+	if c.DialBackoffJitter < 0 || c.DialBackoffJitter > 1 {
+		return fmt.Errorf("%w: DialBackoffJitter must be in [0, 1], got %g", errs.ErrInvalidConfig, c.DialBackoffJitter)
+	}
+	if c.MinIdle < 0 || c.MinIdle > c.MaxConnections {
+		return fmt.Errorf("%w: MinIdle must be in [0, MaxConnections], got %d", errs.ErrInvalidConfig, c.MinIdle)
+	}
+	if c.ReservedConnections < 0 || c.ReservedConnections >= c.MaxConnections {
+		return fmt.Errorf("%w: ReservedConnections must be in [0, MaxConnections), got %d", errs.ErrInvalidConfig, c.ReservedConnections)
+	}
+	if c.MaxWaiters < 0 {
+		return fmt.Errorf("%w: MaxWaiters must not be negative, got %d", errs.ErrInvalidConfig, c.MaxWaiters)
+	}
+	if c.LeakThreshold < 0 {
+		return fmt.Errorf("%w: LeakThreshold must not be negative, got %s", errs.ErrInvalidConfig, c.LeakThreshold)
+	}
+	if c.LeakReclaim && c.LeakThreshold == 0 {
+		return fmt.Errorf("%w: LeakReclaim requires LeakThreshold", errs.ErrInvalidConfig)
+	}
+	return nil
 }
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"context"
+	"time"
+)
+
+// CloseReason 连接被连接池关闭的原因
+type CloseReason string
+
+const (
+	// CloseReasonIdle 空闲超过 MaxIdleTime
+	CloseReasonIdle CloseReason = "idle"
+	// CloseReasonUnhealthy 健康检查失败
+	CloseReasonUnhealthy CloseReason = "unhealthy"
+	// CloseReasonResetFailed 归还时重置失败
+	CloseReasonResetFailed CloseReason = "reset_failed"
+	// CloseReasonExpired 超过 MaxLifetime
+	CloseReasonExpired CloseReason = "expired"
+	// CloseReasonRejected 被 OnAcquire 钩子拒绝
+	CloseReasonRejected CloseReason = "rejected"
+	// CloseReasonPoolClosed 连接池已关闭
+	CloseReasonPoolClosed CloseReason = "pool_closed"
+	// CloseReasonDiscarded 持有方报告连接已损坏
+	CloseReasonDiscarded CloseReason = "discarded"
+	// CloseReasonLeaked 借出超过 LeakThreshold 未归还，被强制回收
+	CloseReasonLeaked CloseReason = "leaked"
+)
+
+// Hooks 连接生命周期钩子，同一事件的多个钩子按注册顺序调用，
+// 连接以 interface{} 传入，使用时需断言为具体类型(如 *sql.Conn、*redis.Client)。
+// 钩子调用时不持有连接池内部锁，OnClose 在连接关闭后调用
+type Hooks struct {
+	// OnCreate 连接创建后调用，返回错误时关闭该连接，视为创建失败
+	OnCreate []func(ctx context.Context, conn interface{}) error
+	// OnAcquire 连接借出前调用，ctx 为 GetConnection 传入的 ctx，
+	// 返回错误时关闭该连接，GetConnection 返回该错误
+	OnAcquire []func(ctx context.Context, conn interface{}) error
+	// OnRelease 连接归还时调用
+	OnRelease []func(conn interface{})
+	// OnClose 连接被关闭并移出连接池时调用
+	OnClose []func(conn interface{}, reason CloseReason)
+	// OnHealthFail 连接健康检查失败时调用，调用前该连接已以 CloseReasonUnhealthy 关闭
+	OnHealthFail []func(conn interface{}, err error)
+	// OnDiscard 持有方报告连接已损坏时调用，调用前该连接已以 CloseReasonDiscarded 关闭
+	OnDiscard []func(conn interface{}, err error)
+	// OnLeak 连接借出超过 LeakThreshold 未归还时调用，stack 为获取该连接的 goroutine 调用栈，
+	// 未注册时通过 log 输出
+	OnLeak []func(conn interface{}, held time.Duration, stack []byte)
+}
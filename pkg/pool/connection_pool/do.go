@@ -0,0 +1,76 @@
+package connection_pool
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+)
+
+// IsConnectionError 默认的连接故障判断：连接不可用、连接已关闭、EOF、网络错误、
+// 连接被重置或断开时返回 true，业务错误(如查询不到数据)与 ctx 超时或取消返回 false
+func IsConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	// context.DeadlineExceeded 同样实现了 net.Error，单次调用超时不代表连接故障
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, ErrConnectionUnhealthy) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// Do 借出连接执行 fn，结束后归还连接，fn panic 时同样归还后继续 panic；
+// fn 返回连接故障错误时销毁该连接而不是归还，返回 fn 的错误
+func (c *Pool[T]) Do(ctx context.Context, fn func(conn T) error) (err error) {
+	h, err := c.GetConnection(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil && c.isConnectionError(err) {
+			h.Discard(err)
+			return
+		}
+		h.Release()
+	}()
+	return fn(h.Conn())
+}
+
+// DoWithRetry 与 Do 相同，fn 返回连接故障错误时换一个连接重新执行，最多执行 attempts 次，
+// attempts 不大于 0 时按 1 次执行，ctx 结束后不再重试，返回最后一次的错误
+func (c *Pool[T]) DoWithRetry(ctx context.Context, attempts int, fn func(conn T) error) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+	var err error
+	for i := 0; i < attempts; i++ {
+		err = c.Do(ctx, fn)
+		if err == nil || !c.isConnectionError(err) || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// isConnectionError 使用自定义判断或默认的 IsConnectionError
+func (c *Pool[T]) isConnectionError(err error) bool {
+	if c.IsConnectionError != nil {
+		return c.IsConnectionError(err)
+	}
+	return IsConnectionError(err)
+}
@@ -0,0 +1,21 @@
+package connection_pool
+
+import "github.com/practice/connection-pool/pkg/pool/errs"
+
+// 连接池通用错误，与 errs 包中的定义相同，方便调用方直接使用
+var (
+	// ErrPoolClosed 连接池已关闭
+	ErrPoolClosed = errs.ErrPoolClosed
+	// ErrAcquireTimeout 在 Timeout 内没有获取到连接
+	ErrAcquireTimeout = errs.ErrAcquireTimeout
+	// ErrPoolExhausted 连接池中没有可用连接，且不再等待
+	ErrPoolExhausted = errs.ErrPoolExhausted
+	// ErrConnectionUnhealthy 连接健康检查失败
+	ErrConnectionUnhealthy = errs.ErrConnectionUnhealthy
+	// ErrInvalidConfig 连接池配置不合法
+	ErrInvalidConfig = errs.ErrInvalidConfig
+	// ErrDoubleRelease 连接已归还过，不能重复归还
+	ErrDoubleRelease = errs.ErrDoubleRelease
+	// ErrUnknownConnection 连接不是从该连接池借出的
+	ErrUnknownConnection = errs.ErrUnknownConnection
+)
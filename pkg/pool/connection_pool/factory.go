@@ -0,0 +1,25 @@
+package connection_pool
+
+import "context"
+
+// Factory 连接工厂，负责创建、检查与关闭连接，连接池引擎 GenericConnectionPool
+// 通过它管理任意类型的连接资源，实现需保证各方法可并发调用
+type Factory[T any] interface {
+	// Dial 创建新连接，返回前应确认连接可用
+	Dial(ctx context.Context) (T, error)
+	// Validate 检查连接是否可用，用于健康检查
+	Validate(ctx context.Context, conn T) error
+	// Close 关闭连接
+	Close(conn T) error
+}
+
+// Resetter 可选接口，Factory 实现后连接归还时会先重置连接状态，重置失败的连接会被关闭
+type Resetter[T any] interface {
+	Reset(ctx context.Context, conn T) error
+}
+
+// Shutdowner 可选接口，Factory 实现后连接池关闭时会在全部连接关闭后调用，
+// 用于释放工厂自身持有的资源
+type Shutdowner interface {
+	Shutdown() error
+}
@@ -0,0 +1,1059 @@
+package connection_pool
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"github.com/practice/connection-pool/pkg/pool/config"
+	"math/rand"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// GenericConnectionPool 通用连接池引擎，实现 IConnectionPool 接口，
+// 连接的创建、检查与关闭交由 Factory 完成
+type GenericConnectionPool[T comparable] struct {
+	// name 连接池名称，用于错误信息
+	name string
+	// idle 空闲连接，最近归还的在末尾
+	idle []T
+	// waiters 等待空闲连接的调用方，按优先级从高到低、同一优先级按到达顺序排列
+	waiters *list.List
+	// config 连接池通用配置
+	config *config.ConnectionConfig
+	// factory 连接工厂
+	factory Factory[T]
+	// connectionNum 记录当下池中的连接数
+	connectionNum int
+	// conns 记录池中每个连接的元数据，不在其中的连接视为已移除
+	conns map[T]*connMeta
+	// borrowed 记录已借出尚未归还的连接，包括借出期间被移除的连接
+	borrowed map[T]*borrow
+	// released 记录空闲连接最近一次归还的调用栈，仅在开启 DebugRelease 时记录
+	released map[T][]byte
+	// reclaimed 记录被泄漏检测强制回收、持有方尚未归还的连接
+	reclaimed map[T]struct{}
+	// closing 已移除、等待释放锁后关闭的连接
+	closing []pendingClose[T]
+	mu      sync.Mutex
+	// creating 记录正在创建的连接数
+	creating int
+	// normalBorrowed 记录低于 PriorityHigh 的调用方借出的连接数
+	normalBorrowed int
+	// closed 连接池是否已关闭
+	closed bool
+	// closeOnce 保证关闭流程只执行一次
+	closeOnce sync.Once
+	// teardownOnce 保证连接与工厂资源只释放一次
+	teardownOnce sync.Once
+	// done 连接池关闭时关闭，通知等待中的调用方与定时任务退出
+	done chan struct{}
+	// ctx 连接池关闭时取消，定时任务创建与检查连接时使用，关闭时不必等待后端超时
+	ctx    context.Context
+	cancel context.CancelFunc
+	// drained 连接池关闭后，借出的连接全部归还时关闭
+	drained chan struct{}
+	// dialFailures 连续创建连接失败的次数，创建成功后清零
+	dialFailures int
+	// lastDialErr 最近一次创建连接失败的错误
+	lastDialErr error
+	// nextDial 开启 DialBackoff 时，创建连接失败后下一次允许创建的时间
+	nextDial time.Time
+	// wg 等待定时任务退出
+	wg sync.WaitGroup
+
+	// 以下为累计统计，由 mu 保护
+	acquireCount int64
+	waitCount    int64
+	waitDuration time.Duration
+	// waitBounds 创建时复制的 WaitBuckets，之后对 WaitBuckets 的修改不影响已创建的连接池
+	waitBounds []time.Duration
+	// waitBuckets 按 waitBounds 划分的等待时间分布，最后一个元素对应超出最大上界的次数
+	waitBuckets         []int64
+	timeouts            int64
+	healthCheckFailures int64
+	idleClosed          int64
+	maxLifetimeClosed   int64
+	refills             int64
+	discarded           int64
+	leaks               int64
+	leaksReclaimed      int64
+
+	// tracer 链路追踪，未配置时为空实现
+	tracer config.Tracer
+}
+
+// connMeta 连接的元数据
+type connMeta struct {
+	// createdAt 创建时间
+	createdAt time.Time
+	// expiresAt 过期时间，未开启 MaxLifetime 时为零值
+	expiresAt time.Time
+	// lastAcquired 最近一次借出时间，从未借出时为零值
+	lastAcquired time.Time
+	// lastReleased 最近一次归还时间，从未借出时为零值
+	lastReleased time.Time
+	// borrowed 是否已借出
+	borrowed bool
+}
+
+// idleSince 返回连接开始空闲的时间，从未借出的连接从创建时开始计算
+func (m *connMeta) idleSince() time.Time {
+	if m.lastReleased.IsZero() {
+		return m.createdAt
+	}
+	return m.lastReleased
+}
+
+// expired 判断连接是否超过最长存活时间
+func (m *connMeta) expired(now time.Time) bool {
+	return !m.expiresAt.IsZero() && now.After(m.expiresAt)
+}
+
+// borrow 一次借出的信息
+type borrow struct {
+	// at 借出时间
+	at time.Time
+	// priority 借出方的优先级
+	priority Priority
+	// ctx 获取连接时 Tracer 返回的 ctx，归还时用于关联链路
+	ctx context.Context
+	// stack 获取连接的 goroutine 调用栈，仅在开启泄漏检测时记录
+	stack []byte
+	// leaked 是否已报告泄漏
+	leaked bool
+	// released 是否正在归还
+	released bool
+	// releaseStack 归还连接的 goroutine 调用栈，仅在开启 DebugRelease 时记录
+	releaseStack []byte
+}
+
+// pendingClose 等待关闭的连接及关闭原因
+type pendingClose[T comparable] struct {
+	conn   T
+	reason config.CloseReason
+}
+
+// waiter 等待空闲连接的调用方
+type waiter[T comparable] struct {
+	// ch 归还的连接直接交给等待方，容量为 1
+	ch chan T
+	// ctx、stack 与 priority 用于交接时登记借出信息
+	ctx      context.Context
+	stack    []byte
+	priority Priority
+	// handed 连接是否已交给该等待方
+	handed bool
+}
+
+// NewGenericConnectionPool 使用 factory 创建连接池，name 用于错误信息，启动时预先创建
+// MinIdle 个连接(至少一个，用于确认后端可用)，之后按需增长到 MaxConnections，
+// Lazy 模式下后端不可用时以降级状态启动。连接池持有 factory，创建失败或关闭时
+// 会调用其 Shutdown（如已实现）
+func NewGenericConnectionPool[T comparable](name string, factory Factory[T], cfg *config.ConnectionConfig) (*GenericConnectionPool[T], error) {
+	if err := cfg.Validate(); err != nil {
+		shutdownFactory(factory)
+		return nil, fmt.Errorf("failed to create %s connection pool: %w", name, err)
+	}
+
+	p := &GenericConnectionPool[T]{
+		name:        name,
+		idle:        make([]T, 0, cfg.MaxConnections),
+		waiters:     list.New(),
+		config:      cfg,
+		factory:     factory,
+		conns:       make(map[T]*connMeta),
+		borrowed:    make(map[T]*borrow),
+		released:    make(map[T][]byte),
+		reclaimed:   make(map[T]struct{}),
+		waitBounds:  append([]time.Duration(nil), WaitBuckets...),
+		waitBuckets: make([]int64, len(WaitBuckets)+1),
+		done:        make(chan struct{}),
+		drained:     make(chan struct{}),
+		tracer:      cfg.Tracer,
+	}
+	if p.tracer == nil {
+		p.tracer = noopTracer{}
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+
+	// 1. 预先创建 MinIdle 个连接
+	warmup := cfg.MinIdle
+	if warmup == 0 {
+		warmup = 1
+	}
+	for i := 0; i < warmup; i++ {
+		conn, err := p.newConnection()
+		if err != nil {
+			// 懒加载模式下不报错，由定时任务在后端恢复后补充连接
+			if cfg.Lazy {
+				break
+			}
+			for conn := range p.conns {
+				factory.Close(conn)
+			}
+			p.cancel()
+			shutdownFactory(factory)
+			return nil, fmt.Errorf("failed to create %s connection pool: %w", name, err)
+		}
+		p.register(conn)
+		p.idle = append(p.idle, conn)
+	}
+
+	// 2. 启动定时任务
+	p.wg.Add(2)
+	go p.startCleanupTask(cfg.CleanupInterval)
+	go p.startCheckAndModifyConnectionNum()
+	if !cfg.DisableTestWhileIdle {
+		p.wg.Add(1)
+		go p.startHealthCheckTask()
+	}
+	if cfg.LeakThreshold > 0 {
+		p.wg.Add(1)
+		go p.startLeakDetectionTask()
+	}
+
+	return p, nil
+}
+
+// shutdownFactory 释放工厂自身持有的资源
+func shutdownFactory(factory interface{}) {
+	if s, ok := factory.(Shutdowner); ok {
+		s.Shutdown()
+	}
+}
+
+// newConnection 通过工厂创建连接并调用 OnCreate 钩子，最长等待 Timeout
+func (p *GenericConnectionPool[T]) newConnection() (T, error) {
+	ctx, cancel := context.WithTimeout(p.ctx, p.config.Timeout)
+	defer cancel()
+	conn, err := p.factory.Dial(ctx)
+	p.mu.Lock()
+	p.observeDial(err)
+	p.mu.Unlock()
+	if err != nil {
+		return conn, err
+	}
+	if err := p.runOnCreate(ctx, conn); err != nil {
+		p.factory.Close(conn)
+		var zero T
+		return zero, fmt.Errorf("%s connection rejected by OnCreate hook: %w", p.name, err)
+	}
+	return conn, nil
+}
+
+// observeDial 记录一次创建连接的结果，开启 DialBackoff 时连续失败后按指数退避推迟下一次创建，
+// 调用方需持有 mu
+func (p *GenericConnectionPool[T]) observeDial(err error) {
+	if err == nil {
+		p.dialFailures = 0
+		p.nextDial = time.Time{}
+		return
+	}
+	p.dialFailures++
+	p.lastDialErr = err
+	if p.config.DialBackoff > 0 {
+		p.nextDial = time.Now().Add(p.dialBackoff(p.dialFailures))
+	}
+}
+
+// dialBackoff 返回连续失败 failures 次后的退避时间，从 DialBackoff 开始每次翻倍，
+// 不超过 DialBackoffMax，并按 DialBackoffJitter 随机缩短
+func (p *GenericConnectionPool[T]) dialBackoff(failures int) time.Duration {
+	delay := p.config.DialBackoff
+	for i := 1; i < failures && delay < p.config.DialBackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > p.config.DialBackoffMax {
+		delay = p.config.DialBackoffMax
+	}
+	if p.config.DialBackoffJitter > 0 {
+		delay -= time.Duration(rand.Float64() * p.config.DialBackoffJitter * float64(delay))
+	}
+	return delay
+}
+
+// backingOff 判断是否处于创建连接失败后的退避期间，调用方需持有 mu
+func (p *GenericConnectionPool[T]) backingOff() bool {
+	return time.Now().Before(p.nextDial)
+}
+
+// GetConnection 从连接池获取连接，等待时间取 ctx 截止时间与 Timeout 中较早者，
+// 等待的调用方超过 MaxWaiters 时立即返回 ErrPoolExhausted
+func (p *GenericConnectionPool[T]) GetConnection(ctx context.Context) (T, error) {
+	return p.acquire(ctx, true)
+}
+
+// TryGetConnection 从连接池获取连接，没有空闲连接时立即返回 ErrPoolExhausted
+func (p *GenericConnectionPool[T]) TryGetConnection(ctx context.Context) (T, error) {
+	return p.acquire(ctx, false)
+}
+
+// acquire 获取空闲连接，wait 为 false 时不等待
+func (p *GenericConnectionPool[T]) acquire(ctx context.Context, wait bool) (_ T, err error) {
+	var zero T
+	ctx, endAcquire := p.tracer.StartAcquire(ctx, p.name)
+	var stack []byte
+	if p.config.LeakThreshold > 0 {
+		stack = debug.Stack()
+	}
+	// 记录等待时间分布，没有空闲连接需要等待时同时记录等待次数与等待时间
+	var waited time.Duration
+	defer func() {
+		p.mu.Lock()
+		p.observeWait(waited)
+		p.mu.Unlock()
+		endAcquire(waited, err)
+	}()
+
+	// 借出前检查失败时换一个连接，重新排队时排在同一优先级最前，总等待时间不超过 Timeout
+	deadline := time.Now().Add(p.config.Timeout)
+	priority := PriorityFromContext(ctx)
+	for retry := false; ; retry = true {
+		conn, w, err := p.take(ctx, wait, stack, priority, deadline, retry)
+		waited += w
+		if err != nil {
+			return zero, err
+		}
+		ok, err := p.testOnBorrow(ctx, conn)
+		if err != nil {
+			return zero, err
+		}
+		if !ok {
+			continue
+		}
+
+		if err := p.runOnAcquire(ctx, conn); err != nil {
+			p.mu.Lock()
+			p.endBorrow(conn)
+			if _, alive := p.conns[conn]; alive {
+				p.removeConnection(conn, config.CloseReasonRejected)
+			}
+			p.unlock()
+			return zero, fmt.Errorf("failed to get %s connection: %w", p.name, err)
+		}
+		return conn, nil
+	}
+}
+
+// take 借出一个空闲连接，没有空闲连接时排队等待到 deadline，wait 为 false 时不等待，
+// retry 为 true 时表示借出的连接检查失败后重新获取，排在同一优先级的等待方之前且不受 MaxWaiters 限制，
+// 返回等待空闲连接的时间
+func (p *GenericConnectionPool[T]) take(ctx context.Context, wait bool, stack []byte, priority Priority, deadline time.Time, retry bool) (T, time.Duration, error) {
+	var zero T
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return zero, 0, fmt.Errorf("failed to get %s connection: %w", p.name, ErrPoolClosed)
+	}
+	if n := len(p.idle); n > 0 && p.eligible(priority) {
+		conn := p.idle[n-1]
+		p.idle = p.idle[:n-1]
+		p.lend(conn, ctx, stack, priority)
+		p.mu.Unlock()
+		return conn, 0, nil
+	}
+	if !wait {
+		// 不等待新连接创建，但仍按需增长，供之后的调用方使用
+		p.grow()
+		p.mu.Unlock()
+		return zero, 0, fmt.Errorf("failed to get %s connection: %w", p.name, ErrPoolExhausted)
+	}
+	if !retry && p.config.MaxWaiters > 0 && p.waiters.Len() >= p.config.MaxWaiters {
+		p.mu.Unlock()
+		return zero, 0, fmt.Errorf("failed to get %s connection: too many waiters: %w", p.name, ErrPoolExhausted)
+	}
+	w := &waiter[T]{ch: make(chan T, 1), ctx: ctx, stack: stack, priority: priority}
+	elem := p.enqueue(w, retry)
+	p.grow()
+	p.mu.Unlock()
+
+	waitStart := time.Now()
+	conn, err := p.wait(ctx, w, elem, deadline)
+	return conn, time.Since(waitStart), err
+}
+
+// testOnBorrow 开启 TestOnBorrow 时检查借出的连接，SkipValidationWithin 内归还或新建的连接跳过检查，
+// 检查失败时关闭该连接并返回 false。调用方 ctx 结束导致的失败不代表连接故障，
+// 连接放回空闲连接并返回 ctx 的错误
+func (p *GenericConnectionPool[T]) testOnBorrow(ctx context.Context, conn T) (bool, error) {
+	if !p.config.TestOnBorrow {
+		return true, nil
+	}
+	p.mu.Lock()
+	meta, alive := p.conns[conn]
+	skip := alive && p.recentlyUsed(meta.idleSince())
+	p.mu.Unlock()
+	if skip {
+		return true, nil
+	}
+
+	err := p.validate(ctx, conn)
+	if err == nil {
+		return true, nil
+	}
+	p.mu.Lock()
+	p.endBorrow(conn)
+	_, alive = p.conns[conn]
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		if alive {
+			p.putIdle(conn)
+		}
+		p.unlock()
+		return false, fmt.Errorf("failed to get %s connection: %w", p.name, ctxErr)
+	}
+	if alive {
+		p.removeConnection(conn, config.CloseReasonUnhealthy)
+		p.healthCheckFailures++
+	}
+	p.unlock()
+	if alive {
+		p.runOnHealthFail(conn, err)
+	}
+	return false, nil
+}
+
+// recentlyUsed 判断连接最近一次使用是否在 SkipValidationWithin 内
+func (p *GenericConnectionPool[T]) recentlyUsed(used time.Time) bool {
+	return p.config.SkipValidationWithin > 0 && time.Since(used) < p.config.SkipValidationWithin
+}
+
+// wait 排队等待归还的连接，等待时间取 ctx 截止时间与 deadline 中较早者
+func (p *GenericConnectionPool[T]) wait(ctx context.Context, w *waiter[T], elem *list.Element, deadline time.Time) (T, error) {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	var err error
+	select {
+	case conn := <-w.ch:
+		return conn, nil
+	case <-p.done:
+		err = ErrPoolClosed
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-timer.C:
+		err = ErrAcquireTimeout
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	// 放弃等待前连接已交接，仍然使用该连接
+	if w.handed {
+		return <-w.ch, nil
+	}
+	p.waiters.Remove(elem)
+	if err == ErrAcquireTimeout {
+		p.timeouts++
+	}
+	var zero T
+	return zero, fmt.Errorf("failed to get %s connection: %w", p.name, err)
+}
+
+// lend 登记借出信息，调用方需持有 mu 并确认连接池未关闭
+func (p *GenericConnectionPool[T]) lend(conn T, ctx context.Context, stack []byte, priority Priority) {
+	now := time.Now()
+	if meta, ok := p.conns[conn]; ok {
+		meta.lastAcquired = now
+		meta.borrowed = true
+	}
+	p.borrowed[conn] = &borrow{at: now, ctx: ctx, stack: stack, priority: priority}
+	delete(p.released, conn)
+	if priority < PriorityHigh {
+		p.normalBorrowed++
+	}
+}
+
+// eligible 判断该优先级的调用方能否借出连接，ReservedConnections 个连接只借给高优先级调用方，
+// 调用方需持有 mu
+func (p *GenericConnectionPool[T]) eligible(priority Priority) bool {
+	return priority >= PriorityHigh || p.normalBorrowed < p.config.MaxConnections-p.config.ReservedConnections
+}
+
+// enqueue 按优先级将等待方插入队列，同一优先级排在已有等待方之后，first 为 true 时排在最前，
+// 调用方需持有 mu
+func (p *GenericConnectionPool[T]) enqueue(w *waiter[T], first bool) *list.Element {
+	for e := p.waiters.Back(); e != nil; e = e.Prev() {
+		priority := e.Value.(*waiter[T]).priority
+		if priority > w.priority || (priority == w.priority && !first) {
+			return p.waiters.InsertAfter(w, e)
+		}
+	}
+	return p.waiters.PushFront(w)
+}
+
+// putIdle 放回空闲连接并交给等待方，连接池已关闭时移除该连接，调用方需持有 mu
+func (p *GenericConnectionPool[T]) putIdle(conn T) {
+	if p.closed {
+		p.removeConnection(conn, config.CloseReasonPoolClosed)
+		return
+	}
+	p.idle = append(p.idle, conn)
+	p.dispatch()
+}
+
+// dispatch 将空闲连接直接交给排在最前、可以借出连接的等待方，连接池关闭后不再交接，
+// 调用方需持有 mu
+func (p *GenericConnectionPool[T]) dispatch() {
+	for len(p.idle) > 0 && !p.closed {
+		var next *list.Element
+		for e := p.waiters.Front(); e != nil; e = e.Next() {
+			if p.eligible(e.Value.(*waiter[T]).priority) {
+				next = e
+				break
+			}
+		}
+		if next == nil {
+			return
+		}
+		w := p.waiters.Remove(next).(*waiter[T])
+		conn := p.idle[len(p.idle)-1]
+		p.idle = p.idle[:len(p.idle)-1]
+		p.lend(conn, w.ctx, w.stack, w.priority)
+		w.handed = true
+		w.ch <- conn
+	}
+}
+
+// observeWait 记录一次获取连接的等待时间，调用方需持有 mu
+func (p *GenericConnectionPool[T]) observeWait(wait time.Duration) {
+	p.acquireCount++
+	if wait > 0 {
+		p.waitCount++
+		p.waitDuration += wait
+	}
+	i := 0
+	for i < len(p.waitBounds) && wait > p.waitBounds[i] {
+		i++
+	}
+	p.waitBuckets[i]++
+}
+
+// ReleaseConnection 释放连接到连接池，工厂实现 Resetter 时先重置连接，
+// 重置失败或连接池已关闭时直接关闭该连接。重复释放或释放不属于该连接池的连接时
+// 返回错误，开启 PanicOnInvalidRelease 时 panic；连接被再次借出后的重复释放无法识别，
+// 见 IConnectionPool.ReleaseConnection
+func (p *GenericConnectionPool[T]) ReleaseConnection(conn T) error {
+	p.mu.Lock()
+	b, ok := p.borrowed[conn]
+	if !ok || b.released {
+		err := p.invalidRelease(conn, b)
+		p.mu.Unlock()
+		return p.rejectRelease(err)
+	}
+	// 标记为归还中，重置期间的重复归还同样会被拒绝
+	b.released = true
+	if p.config.DebugRelease {
+		b.releaseStack = debug.Stack()
+	}
+	// 借出时间在 SkipValidationWithin 内时跳过归还检查
+	testOnReturn := p.config.TestOnReturn && !p.recentlyUsed(b.at)
+	p.mu.Unlock()
+
+	p.runOnRelease(conn)
+
+	// 重置与检查使用连接池的 ctx，Close 时取消，不必等待后端超时
+	var resetErr, validateErr error
+	if r, ok := p.factory.(Resetter[T]); ok {
+		ctx, cancel := context.WithTimeout(p.ctx, p.config.Timeout)
+		resetErr = r.Reset(ctx, conn)
+		cancel()
+	}
+	if resetErr == nil && testOnReturn {
+		validateErr = p.validate(p.ctx, conn)
+	}
+
+	// 释放锁后再记录链路
+	held := time.Since(b.at)
+	defer p.tracer.Release(b.ctx, p.name, held)
+
+	if validateErr != nil {
+		p.mu.Lock()
+		p.endBorrow(conn)
+		_, alive := p.conns[conn]
+		// 连接池关闭取消的检查不计为失败
+		failed := alive && !p.closed
+		if failed {
+			p.removeConnection(conn, config.CloseReasonUnhealthy)
+			p.healthCheckFailures++
+		} else if alive {
+			p.removeConnection(conn, config.CloseReasonPoolClosed)
+		}
+		p.unlock()
+		if failed {
+			p.runOnHealthFail(conn, validateErr)
+		}
+		return nil
+	}
+
+	p.mu.Lock()
+	defer p.unlock()
+
+	p.endBorrow(conn)
+	// 连接已被回收或健康检查移除
+	meta, alive := p.conns[conn]
+	if !alive {
+		return nil
+	}
+	if p.closed {
+		p.removeConnection(conn, config.CloseReasonPoolClosed)
+		return nil
+	}
+	if resetErr != nil {
+		p.removeConnection(conn, config.CloseReasonResetFailed)
+		return nil
+	}
+	now := time.Now()
+	if meta.expired(now) {
+		p.removeConnection(conn, config.CloseReasonExpired)
+		p.maxLifetimeClosed++
+		return nil
+	}
+	if b.releaseStack != nil {
+		p.released[conn] = b.releaseStack
+	}
+	meta.lastReleased = now
+	p.putIdle(conn)
+	return nil
+}
+
+// DiscardConnection 归还已损坏的连接，连接被关闭并由定时任务补充，err 传给 OnDiscard 钩子
+func (p *GenericConnectionPool[T]) DiscardConnection(conn T, err error) error {
+	p.mu.Lock()
+	b, ok := p.borrowed[conn]
+	if !ok || b.released {
+		releaseErr := p.invalidRelease(conn, b)
+		p.mu.Unlock()
+		return p.rejectRelease(releaseErr)
+	}
+	held := time.Since(b.at)
+	p.endBorrow(conn)
+	_, alive := p.conns[conn]
+	if alive {
+		p.removeConnection(conn, config.CloseReasonDiscarded)
+		p.discarded++
+	}
+	p.unlock()
+
+	p.tracer.Release(b.ctx, p.name, held)
+	if alive {
+		p.runOnDiscard(conn, err)
+	}
+	return nil
+}
+
+// rejectRelease 开启 PanicOnInvalidRelease 时对归还错误 panic，否则原样返回
+func (p *GenericConnectionPool[T]) rejectRelease(err error) error {
+	if err != nil && p.config.PanicOnInvalidRelease {
+		panic(err)
+	}
+	return err
+}
+
+// invalidRelease 判断未借出连接的归还属于哪种错误，b 为正在归还中的借出信息，
+// 被泄漏检测强制回收的连接归还时不视为错误，调用方需持有 mu
+func (p *GenericConnectionPool[T]) invalidRelease(conn T, b *borrow) error {
+	if _, ok := p.reclaimed[conn]; ok {
+		delete(p.reclaimed, conn)
+		return nil
+	}
+
+	_, alive := p.conns[conn]
+	if b == nil && !alive {
+		return fmt.Errorf("failed to release %s connection: %w", p.name, ErrUnknownConnection)
+	}
+	var stack []byte
+	if b != nil {
+		stack = b.releaseStack
+	} else {
+		stack = p.released[conn]
+	}
+	if stack != nil {
+		return fmt.Errorf("failed to release %s connection: %w, first released at:\n%s", p.name, ErrDoubleRelease, stack)
+	}
+	return fmt.Errorf("failed to release %s connection: %w", p.name, ErrDoubleRelease)
+}
+
+// endBorrow 结束一次借出，连接池已关闭且借出的连接全部归还时通知 Close，
+// 释放的名额可能让等待中的调用方借出空闲连接，调用方需持有 mu
+func (p *GenericConnectionPool[T]) endBorrow(conn T) {
+	b, ok := p.borrowed[conn]
+	if !ok {
+		return
+	}
+	delete(p.borrowed, conn)
+	if meta, ok := p.conns[conn]; ok {
+		meta.borrowed = false
+	}
+	if b.priority < PriorityHigh {
+		p.normalBorrowed--
+		p.dispatch()
+	}
+	if p.closed && len(p.borrowed) == 0 {
+		close(p.drained)
+	}
+}
+
+// removeConnection 将连接从连接池中移除，连接在 unlock 释放锁后关闭并调用 OnClose 钩子，
+// 调用方需持有 mu
+func (p *GenericConnectionPool[T]) removeConnection(conn T, reason config.CloseReason) {
+	p.takeIdle(conn)
+	delete(p.conns, conn)
+	delete(p.released, conn)
+	p.connectionNum--
+	p.closing = append(p.closing, pendingClose[T]{conn: conn, reason: reason})
+	// 有调用方在等待时补充被移除的连接
+	if p.waiters.Len() > 0 {
+		p.grow()
+	}
+}
+
+// unlock 释放 mu 后关闭期间移除的连接，关闭连接可能较慢，不能阻塞其他调用方
+func (p *GenericConnectionPool[T]) unlock() {
+	conns := p.closing
+	p.closing = nil
+	p.mu.Unlock()
+
+	for _, c := range conns {
+		p.factory.Close(c.conn)
+		p.runOnClose(c.conn, c.reason)
+	}
+}
+
+// Stats 返回连接池统计信息快照
+func (p *GenericConnectionPool[T]) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return Stats{
+		TotalConnections:    p.connectionNum,
+		IdleConnections:     len(p.idle),
+		InUseConnections:    p.connectionNum - len(p.idle),
+		CreatingConnections: p.creating,
+		Waiters:             p.waiters.Len(),
+		AcquireCount:        p.acquireCount,
+		WaitCount:           p.waitCount,
+		WaitDuration:        p.waitDuration,
+		WaitHistogram:       p.waitHistogram(),
+		Timeouts:            p.timeouts,
+		HealthCheckFailures: p.healthCheckFailures,
+		IdleClosed:          p.idleClosed,
+		MaxLifetimeClosed:   p.maxLifetimeClosed,
+		Refills:             p.refills,
+		Discarded:           p.discarded,
+		Leaks:               p.leaks,
+		LeaksReclaimed:      p.leaksReclaimed,
+		DialFailures:        p.dialFailures,
+		LastDialError:       p.lastDialErr,
+	}
+}
+
+// waitHistogram 将等待时间分布转为累计计数，调用方需持有 mu
+func (p *GenericConnectionPool[T]) waitHistogram() []WaitBucket {
+	histogram := make([]WaitBucket, len(p.waitBounds))
+	var count int64
+	for i, upperBound := range p.waitBounds {
+		count += p.waitBuckets[i]
+		histogram[i] = WaitBucket{UpperBound: upperBound, Count: count}
+	}
+	return histogram
+}
+
+// Close 关闭连接池：不再接受获取请求，在 ctx 截止前等待借出的连接归还，
+// 随后关闭全部连接、停止定时任务并释放工厂资源，可重复调用，连接与工厂资源只释放一次
+func (p *GenericConnectionPool[T]) Close(ctx context.Context) error {
+	p.closeOnce.Do(func() {
+		p.mu.Lock()
+		p.closed = true
+		close(p.done)
+		p.cancel()
+		if len(p.borrowed) == 0 {
+			close(p.drained)
+		}
+		p.mu.Unlock()
+	})
+
+	// 在 ctx 截止前等待定时任务退出与借出的连接归还
+	stopped := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(stopped)
+	}()
+	var err error
+	for _, ch := range []chan struct{}{stopped, p.drained} {
+		if err != nil {
+			break
+		}
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			err = fmt.Errorf("failed to close %s connection pool: %w", p.name, ctx.Err())
+		}
+	}
+
+	// 关闭全部连接，包括等待超时后仍未归还的连接
+	p.teardownOnce.Do(func() {
+		p.mu.Lock()
+		for conn := range p.conns {
+			p.removeConnection(conn, config.CloseReasonPoolClosed)
+		}
+		p.unlock()
+		shutdownFactory(p.factory)
+	})
+	return err
+}
+
+// reclaimConnections 关闭空闲超过 MaxIdleTime 或超过最长存活时间的空闲连接，空闲超时的回收
+// 保留 MinIdle 个空闲连接，借出的连接不会被回收，过期的借出连接在归还时关闭
+func (p *GenericConnectionPool[T]) reclaimConnections() {
+	p.mu.Lock()
+	defer p.unlock()
+
+	now := time.Now()
+	for _, conn := range append([]T(nil), p.idle...) {
+		meta := p.conns[conn]
+		if meta.expired(now) {
+			p.removeConnection(conn, config.CloseReasonExpired)
+			p.maxLifetimeClosed++
+		} else if now.Sub(meta.idleSince()) > p.config.MaxIdleTime && len(p.idle) > p.minIdle() {
+			p.removeConnection(conn, config.CloseReasonIdle)
+			p.idleClosed++
+		}
+	}
+}
+
+// startCleanupTask 启动定时任务来定期调用回收空闲连接的方法
+func (p *GenericConnectionPool[T]) startCleanupTask(interval time.Duration) {
+	defer p.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.reclaimConnections()
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// startHealthCheckTask 启动定时任务来定期进行连接池的健康检查
+func (p *GenericConnectionPool[T]) startHealthCheckTask() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.config.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.checkConnectionsHealth()
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// checkConnectionsHealth 检查空闲连接的健康状态，借出的连接与 SkipValidationWithin 内归还的连接不检查，
+// 检查期间该连接从空闲连接中取出，不会被借出，检查期间不持有锁
+func (p *GenericConnectionPool[T]) checkConnectionsHealth() {
+	p.mu.Lock()
+	conns := append([]T(nil), p.idle...)
+	p.mu.Unlock()
+
+	ctx, endHealthCheck := p.tracer.StartHealthCheck(p.ctx, p.name)
+	failed := 0
+
+	checked := 0
+	defer func() {
+		endHealthCheck(checked, failed)
+	}()
+
+	for _, conn := range conns {
+		// 连接池关闭后不再检查
+		if p.isDone() {
+			return
+		}
+		// 跳过已被借出、移除或最近使用过的连接
+		p.mu.Lock()
+		if meta, alive := p.conns[conn]; !alive || p.recentlyUsed(meta.idleSince()) || !p.takeIdle(conn) {
+			p.mu.Unlock()
+			continue
+		}
+		p.mu.Unlock()
+
+		// 健康检查逻辑
+		err := p.validate(ctx, conn)
+		checked++
+
+		p.mu.Lock()
+		meta := p.conns[conn]
+		if p.closed {
+			// 检查期间连接池已关闭，检查被取消，不计为失败；Close 等待超时时该连接可能已被移除
+			if _, alive := p.conns[conn]; alive {
+				p.removeConnection(conn, config.CloseReasonPoolClosed)
+			}
+			p.unlock()
+			return
+		}
+		if err != nil {
+			// 连接无效，关闭连接并从连接池中移除
+			p.removeConnection(conn, config.CloseReasonUnhealthy)
+			p.healthCheckFailures++
+			failed++
+		} else if time.Since(meta.idleSince()) > p.config.MaxIdleTime && len(p.idle) >= p.minIdle() {
+			// 连接超时且其余空闲连接不少于 MinIdle，关闭连接并从连接池中移除
+			p.removeConnection(conn, config.CloseReasonIdle)
+			p.idleClosed++
+		} else {
+			p.putIdle(conn)
+		}
+		p.unlock()
+
+		if err != nil {
+			p.runOnHealthFail(conn, err)
+		}
+	}
+}
+
+// isDone 判断连接池是否已关闭，供定时任务在逐个处理连接时提前退出
+func (p *GenericConnectionPool[T]) isDone() bool {
+	select {
+	case <-p.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// takeIdle 从空闲连接中取出指定连接，连接不在空闲连接中时返回 false，调用方需持有 mu
+func (p *GenericConnectionPool[T]) takeIdle(conn T) bool {
+	for i, idle := range p.idle {
+		if idle == conn {
+			p.idle = append(p.idle[:i], p.idle[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// validate 通过工厂检查连接是否可用，最长等待 Timeout
+func (p *GenericConnectionPool[T]) validate(ctx context.Context, conn T) error {
+	ctx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+	defer cancel()
+	return p.factory.Validate(ctx, conn)
+}
+
+// startCheckAndModifyConnectionNum 启动定时任务来定期进行连接池的连接数检查
+func (p *GenericConnectionPool[T]) startCheckAndModifyConnectionNum() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.config.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.checkAndModifyConnectionNum()
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// grow 连接数未达 MaxConnections 且不在退避期间时在后台创建一个连接，创建后交给等待方或放入空闲连接，
+// 调用方需持有 mu
+func (p *GenericConnectionPool[T]) grow() {
+	if p.closed || p.connectionNum+p.creating >= p.config.MaxConnections || p.backingOff() {
+		return
+	}
+	p.creating++
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		conn, err := p.newConnection()
+		p.mu.Lock()
+		p.creating--
+		p.mu.Unlock()
+		if err != nil {
+			return
+		}
+		if !p.addConnection(conn) {
+			p.factory.Close(conn)
+		}
+	}()
+}
+
+// checkAndModifyConnectionNum 检查连接池中连接数量，空闲连接少于 MinIdle 或有调用方在等待时
+// 补充连接，总数不超过 MaxConnections，创建失败后的退避期间不补充，创建期间不持有锁
+func (p *GenericConnectionPool[T]) checkAndModifyConnectionNum() {
+	p.mu.Lock()
+	if p.backingOff() {
+		p.mu.Unlock()
+		return
+	}
+	newConnectionNum := p.minIdle() - len(p.idle)
+	if waiters := p.waiters.Len(); waiters > newConnectionNum {
+		newConnectionNum = waiters
+	}
+	newConnectionNum -= p.creating
+	if available := p.config.MaxConnections - p.connectionNum - p.creating; available < newConnectionNum {
+		newConnectionNum = available
+	}
+	p.mu.Unlock()
+
+	for i := 0; i < newConnectionNum && !p.isDone(); i++ {
+		p.mu.Lock()
+		p.creating++
+		p.mu.Unlock()
+		conn, err := p.newConnection()
+		p.mu.Lock()
+		p.creating--
+		p.mu.Unlock()
+		if err != nil {
+			// 失败已由 newConnection 记录，等待下一次检查
+			return
+		}
+		if !p.addConnection(conn) {
+			p.factory.Close(conn)
+			return
+		}
+		p.mu.Lock()
+		p.refills++
+		p.mu.Unlock()
+	}
+}
+
+// minIdle 返回需要保持的最少空闲连接数，懒加载模式下至少为 1，保证后端恢复后由定时任务建立连接
+func (p *GenericConnectionPool[T]) minIdle() int {
+	if p.config.Lazy && p.config.MinIdle == 0 {
+		return 1
+	}
+	return p.config.MinIdle
+}
+
+// addConnection 将新建连接交给等待方或放入空闲连接，连接池已关闭或已满时返回 false
+func (p *GenericConnectionPool[T]) addConnection(conn T) bool {
+	p.mu.Lock()
+	defer p.unlock()
+
+	if p.closed || p.connectionNum >= p.config.MaxConnections {
+		return false
+	}
+	p.register(conn)
+	p.putIdle(conn)
+	return true
+}
+
+// register 登记新建的连接，开启 MaxLifetime 时为其生成带随机抖动的过期时间，调用方需持有 mu
+func (p *GenericConnectionPool[T]) register(conn T) {
+	meta := &connMeta{createdAt: time.Now()}
+	if p.config.MaxLifetime > 0 {
+		lifetime := p.config.MaxLifetime
+		if p.config.LifetimeJitter > 0 {
+			lifetime -= time.Duration(rand.Int63n(int64(p.config.LifetimeJitter) + 1))
+		}
+		meta.expiresAt = meta.createdAt.Add(lifetime)
+	}
+	p.conns[conn] = meta
+	p.connectionNum++
+}
@@ -0,0 +1,1589 @@
+package connection_pool
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/practice/connection-pool/pkg/pool/config"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeConn 测试用连接
+type fakeConn struct {
+	id     int
+	closed bool
+	closes int
+}
+
+// fakeFactory 测试用连接工厂，可模拟后端不可用与连接失效
+type fakeFactory struct {
+	mu        sync.Mutex
+	attempts  int
+	dialed    int
+	down      bool
+	broken    map[*fakeConn]bool
+	shutdowns int
+	// hang 模拟后端无响应，Dial 与 Validate 阻塞到 ctx 结束
+	hang bool
+	// closeBlock 不为 nil 时 Close 阻塞到其关闭
+	closeBlock chan struct{}
+	// validateBlock 不为 nil 时 Validate 忽略 ctx 阻塞到其关闭
+	validateBlock chan struct{}
+}
+
+func newFakeFactory() *fakeFactory {
+	return &fakeFactory{broken: make(map[*fakeConn]bool)}
+}
+
+func (f *fakeFactory) Dial(ctx context.Context) (*fakeConn, error) {
+	f.mu.Lock()
+	f.attempts++
+	hang := f.hang
+	f.mu.Unlock()
+	if hang {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.down {
+		return nil, errors.New("backend is down")
+	}
+	f.dialed++
+	return &fakeConn{id: f.dialed}, nil
+}
+
+func (f *fakeFactory) Validate(ctx context.Context, conn *fakeConn) error {
+	f.mu.Lock()
+	hang, block := f.hang, f.validateBlock
+	f.mu.Unlock()
+	if hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if block != nil {
+		<-block
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.broken[conn] {
+		return ErrConnectionUnhealthy
+	}
+	return nil
+}
+
+func (f *fakeFactory) Close(conn *fakeConn) error {
+	f.mu.Lock()
+	block := f.closeBlock
+	f.mu.Unlock()
+	if block != nil {
+		<-block
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	conn.closed = true
+	conn.closes++
+	return nil
+}
+
+func (f *fakeFactory) Shutdown() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.shutdowns++
+	return nil
+}
+
+func (f *fakeFactory) setDown(down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down = down
+}
+
+func (f *fakeFactory) setHang(hang bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hang = hang
+}
+
+func (f *fakeFactory) setBroken(conn *fakeConn) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.broken[conn] = true
+}
+
+func (f *fakeFactory) isClosed(conn *fakeConn) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return conn.closed
+}
+
+func (f *fakeFactory) closeCount(conn *fakeConn) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return conn.closes
+}
+
+func (f *fakeFactory) dialAttempts() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.attempts
+}
+
+func (f *fakeFactory) isShutdown() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.shutdowns > 0
+}
+
+func (f *fakeFactory) shutdownCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.shutdowns
+}
+
+func (f *fakeFactory) blockValidate() chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.validateBlock = make(chan struct{})
+	return f.validateBlock
+}
+
+func (f *fakeFactory) blockClose() chan struct{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closeBlock = make(chan struct{})
+	return f.closeBlock
+}
+
+// resetFactory 实现 Resetter 的测试用连接工厂，可模拟重置失败与重置无响应
+type resetFactory struct {
+	*fakeFactory
+	resets int
+	// resetFail 重置失败的连接
+	resetFail map[*fakeConn]bool
+	// resetHang 模拟后端无响应，Reset 阻塞到 ctx 结束
+	resetHang bool
+}
+
+func newResetFactory() *resetFactory {
+	return &resetFactory{fakeFactory: newFakeFactory(), resetFail: make(map[*fakeConn]bool)}
+}
+
+func (f *resetFactory) Reset(ctx context.Context, conn *fakeConn) error {
+	f.mu.Lock()
+	f.resets++
+	hang := f.resetHang
+	f.mu.Unlock()
+	if hang {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.resetFail[conn] {
+		return errors.New("reset failed")
+	}
+	return nil
+}
+
+func (f *resetFactory) setResetFail(conn *fakeConn) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resetFail[conn] = true
+}
+
+func (f *resetFactory) setResetHang(hang bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.resetHang = hang
+}
+
+func (f *resetFactory) resetCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.resets
+}
+
+func newTestConfig(maxConnections int) *config.ConnectionConfig {
+	return &config.ConnectionConfig{
+		MaxConnections:      maxConnections,
+		MinIdle:             maxConnections,
+		Timeout:             100 * time.Millisecond,
+		MaxIdleTime:         time.Minute,
+		HealthCheckInterval: 20 * time.Millisecond,
+		CleanupInterval:     time.Minute,
+	}
+}
+
+func TestGenericConnectionPoolAcquireTimeout(t *testing.T) {
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", newFakeFactory(), newTestConfig(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	conn, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.GetConnection(context.Background()); !errors.Is(err, ErrAcquireTimeout) {
+		t.Fatalf("expected ErrAcquireTimeout, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := p.GetConnection(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+
+	p.ReleaseConnection(conn)
+	conn, err = p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.ReleaseConnection(conn)
+}
+
+func TestGenericConnectionPoolLazy(t *testing.T) {
+	factory := newFakeFactory()
+	factory.setDown(true)
+	cfg := newTestConfig(2)
+	if _, err := NewGenericConnectionPool[*fakeConn]("fake", factory, cfg); err == nil {
+		t.Fatal("expected error when backend is down")
+	}
+
+	cfg.Lazy = true
+	cfg.MinIdle = 0
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	// 后端恢复后由定时任务补充连接，不依赖获取连接触发
+	factory.setDown(false)
+	deadline := time.Now().Add(time.Second)
+	for p.Stats().IdleConnections == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("pool did not reconnect in the background: %+v", p.Stats())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for {
+		conn, err := p.GetConnection(ctx)
+		if err == nil {
+			p.ReleaseConnection(conn)
+			return
+		}
+		if ctx.Err() != nil {
+			t.Fatalf("pool did not recover: %v", err)
+		}
+	}
+}
+
+func TestGenericConnectionPoolHealthCheck(t *testing.T) {
+	factory := newFakeFactory()
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, newTestConfig(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	conn, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory.setBroken(conn)
+	p.ReleaseConnection(conn)
+
+	// 失效连接被移除，并补充新连接
+	time.Sleep(100 * time.Millisecond)
+	if !factory.isClosed(conn) {
+		t.Fatal("broken connection was not closed")
+	}
+	fresh, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh == conn {
+		t.Fatal("got broken connection back")
+	}
+	p.ReleaseConnection(fresh)
+}
+
+func TestGenericConnectionPoolClose(t *testing.T) {
+	factory := newFakeFactory()
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, newTestConfig(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 借出的连接未归还时，Close 等到 ctx 截止
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if !factory.isClosed(conn) || !factory.isShutdown() {
+		t.Fatal("connections and factory should be closed after Close")
+	}
+	if _, err := p.GetConnection(context.Background()); !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("expected ErrPoolClosed, got %v", err)
+	}
+
+	// 关闭后归还连接与重复关闭都是安全的
+	if err := p.ReleaseConnection(conn); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := factory.shutdownCount(); n != 1 {
+		t.Fatalf("expected factory to be shut down once, got %d", n)
+	}
+}
+
+func TestGenericConnectionPoolCloseHungBackend(t *testing.T) {
+	factory := newFakeFactory()
+	cfg := newTestConfig(5)
+	cfg.Timeout = time.Second
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 后端无响应时健康检查阻塞在 Validate，Close 不必等待检查超时
+	factory.setHang(true)
+	time.Sleep(50 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	p.Close(ctx)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Close took %s with a hung backend", elapsed)
+	}
+	if stats := p.Stats(); stats.TotalConnections != 0 || stats.HealthCheckFailures != 0 {
+		t.Fatalf("unexpected stats after Close: %+v", stats)
+	}
+}
+
+func TestGenericConnectionPoolCloseDuringHealthCheck(t *testing.T) {
+	factory := newFakeFactory()
+	cfg := newTestConfig(1)
+	cfg.Timeout = time.Second
+	cfg.HealthCheckInterval = time.Minute
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 模拟健康检查取出唯一的连接，调用方排队等待
+	p.mu.Lock()
+	conn := p.idle[0]
+	p.takeIdle(conn)
+	p.mu.Unlock()
+	waited := make(chan error, 1)
+	go func() {
+		conn, err := p.GetConnection(context.Background())
+		if err == nil {
+			p.ReleaseConnection(conn)
+		}
+		waited <- err
+	}()
+	for p.Stats().Waiters != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// 连接池已标记关闭、等待方尚未退出时检查结束，连接不能交给等待方
+	p.mu.Lock()
+	p.closed = true
+	p.putIdle(conn)
+	p.unlock()
+	if !factory.isClosed(conn) {
+		t.Fatal("expected connection to be closed after the pool was closed")
+	}
+	if stats := p.Stats(); stats.Waiters != 1 || stats.TotalConnections != 0 {
+		t.Fatalf("connection was handed to a waiter after close: %+v", stats)
+	}
+
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-waited; !errors.Is(err, ErrPoolClosed) {
+		t.Fatalf("expected ErrPoolClosed, got %v", err)
+	}
+}
+
+func TestGenericConnectionPoolCloseTimeoutDuringHealthCheck(t *testing.T) {
+	factory := newFakeFactory()
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, newTestConfig(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.mu.Lock()
+	conn := p.idle[0]
+	p.mu.Unlock()
+
+	// 健康检查阻塞在 Validate 时 Close 等待超时，关闭了正在检查的连接
+	unblock := factory.blockValidate()
+	for p.Stats().IdleConnections != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// 检查结束后不能再次关闭该连接
+	close(unblock)
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := factory.closeCount(conn); n != 1 {
+		t.Fatalf("expected connection to be closed once, got %d", n)
+	}
+	if stats := p.Stats(); stats.TotalConnections != 0 || stats.InUseConnections != 0 {
+		t.Fatalf("unexpected stats after Close: %+v", stats)
+	}
+}
+
+func TestGenericConnectionPoolCloseConnOutsideLock(t *testing.T) {
+	factory := newFakeFactory()
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, newTestConfig(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	// 健康检查移除失效连接时关闭连接阻塞，不影响其他调用方获取连接
+	conn, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory.setBroken(conn)
+	unblock := factory.blockClose()
+	defer close(unblock)
+	p.ReleaseConnection(conn)
+	deadline := time.Now().Add(time.Second)
+	for p.Stats().HealthCheckFailures == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("broken connection was not removed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	healthy, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatalf("acquire blocked by a slow close: %v", err)
+	}
+	if healthy == conn {
+		t.Fatal("got broken connection back")
+	}
+	p.ReleaseConnection(healthy)
+}
+
+func TestGenericConnectionPoolStats(t *testing.T) {
+	factory := newFakeFactory()
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, newTestConfig(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	first, _ := p.GetConnection(context.Background())
+	stats := p.Stats()
+	if stats.TotalConnections != 2 || stats.IdleConnections != 1 || stats.InUseConnections != 1 {
+		t.Fatalf("unexpected stats after first acquire: %+v", stats)
+	}
+
+	second, _ := p.GetConnection(context.Background())
+	if _, err := p.GetConnection(context.Background()); !errors.Is(err, ErrAcquireTimeout) {
+		t.Fatalf("expected ErrAcquireTimeout, got %v", err)
+	}
+	stats = p.Stats()
+	if stats.AcquireCount != 3 || stats.WaitCount != 1 || stats.Timeouts != 1 || stats.WaitDuration <= 0 {
+		t.Fatalf("unexpected wait stats: %+v", stats)
+	}
+	// 两次立即获取落在最小的桶，超时等待落在 Timeout 之后的桶
+	if stats.WaitHistogram[0].Count != 2 || stats.WaitHistogram[len(stats.WaitHistogram)-1].Count != 3 {
+		t.Fatalf("unexpected wait histogram: %+v", stats.WaitHistogram)
+	}
+
+	factory.setBroken(first)
+	p.ReleaseConnection(first)
+	p.ReleaseConnection(second)
+	deadline := time.Now().Add(time.Second)
+	for stats = p.Stats(); stats.HealthCheckFailures == 0 || stats.Refills == 0; stats = p.Stats() {
+		if time.Now().After(deadline) {
+			t.Fatalf("broken connection was not replaced: %+v", stats)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// 获取连接时跳过已移除的连接，腾出位置后由定时任务补充
+	conn, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn == first {
+		t.Fatal("got broken connection back")
+	}
+	p.ReleaseConnection(conn)
+	stats = p.Stats()
+	if stats.HealthCheckFailures != 1 || stats.Refills != 1 || stats.InUseConnections != 0 || stats.TotalConnections != 2 {
+		t.Fatalf("unexpected stats after health check: %+v", stats)
+	}
+}
+
+func TestGenericConnectionPoolWaitBucketsChanged(t *testing.T) {
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", newFakeFactory(), newTestConfig(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	// 连接池创建后修改 WaitBuckets 不影响已创建的连接池
+	buckets := WaitBuckets
+	WaitBuckets = append(append([]time.Duration(nil), buckets...), 10*time.Second)
+	defer func() { WaitBuckets = buckets }()
+
+	conn, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.ReleaseConnection(conn)
+	if histogram := p.Stats().WaitHistogram; len(histogram) != len(buckets) || histogram[0].Count != 1 {
+		t.Fatalf("unexpected wait histogram: %+v", histogram)
+	}
+}
+
+func TestGenericConnectionPoolHooks(t *testing.T) {
+	factory := newFakeFactory()
+	cfg := newTestConfig(1)
+
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+	rejectAcquire := errors.New("rejected")
+	var reject bool
+	cfg.Hooks = config.Hooks{
+		OnCreate: []func(ctx context.Context, conn interface{}) error{func(ctx context.Context, conn interface{}) error {
+			record("create")
+			return nil
+		}},
+		OnAcquire: []func(ctx context.Context, conn interface{}) error{func(ctx context.Context, conn interface{}) error {
+			record("acquire")
+			if reject {
+				return rejectAcquire
+			}
+			return nil
+		}},
+		OnRelease: []func(conn interface{}){func(conn interface{}) {
+			record("release")
+		}},
+		OnClose: []func(conn interface{}, reason config.CloseReason){func(conn interface{}, reason config.CloseReason) {
+			record("close:" + string(reason))
+		}},
+		OnHealthFail: []func(conn interface{}, err error){func(conn interface{}, err error) {
+			record("health_fail")
+		}},
+	}
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	conn, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory.setBroken(conn)
+	p.ReleaseConnection(conn)
+
+	// 等待健康检查移除失效连接，并由定时任务补充新连接
+	count := func(event string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		n := 0
+		for _, e := range events {
+			if e == event {
+				n++
+			}
+		}
+		return n
+	}
+	deadline := time.Now().Add(time.Second)
+	for count("health_fail") == 0 || count("create") < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("broken connection was not replaced")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// OnAcquire 返回错误时连接被关闭，GetConnection 返回该错误
+	reject = true
+	conn, err = p.GetConnection(context.Background())
+	if !errors.Is(err, rejectAcquire) {
+		t.Fatalf("expected rejection error, got %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	// 补充连接与健康检查在不同 goroutine 中执行，分别检查两条链路上事件的先后顺序
+	for _, expected := range [][]string{
+		{"create", "acquire", "release", "close:unhealthy", "health_fail", "acquire", "close:rejected"},
+		{"create", "acquire", "release", "create", "acquire", "close:rejected"},
+	} {
+		i := 0
+		for _, event := range events {
+			if i < len(expected) && event == expected[i] {
+				i++
+			}
+		}
+		if i != len(expected) {
+			t.Fatalf("expected events %v in order, got %v", expected, events)
+		}
+	}
+}
+
+func TestGenericConnectionPoolLeakDetection(t *testing.T) {
+	factory := newFakeFactory()
+	cfg := newTestConfig(1)
+	cfg.LeakThreshold = 30 * time.Millisecond
+	cfg.LeakReclaim = true
+	leaks := make(chan []byte, 1)
+	cfg.Hooks.OnLeak = []func(conn interface{}, held time.Duration, stack []byte){
+		func(conn interface{}, held time.Duration, stack []byte) {
+			leaks <- stack
+		},
+	}
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	conn, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case stack := <-leaks:
+		if !bytes.Contains(stack, []byte("TestGenericConnectionPoolLeakDetection")) {
+			t.Fatalf("stack does not point at the acquiring caller:\n%s", stack)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("leak was not reported")
+	}
+
+	// 泄漏的连接被强制回收，连接池恢复容量
+	if !factory.isClosed(conn) {
+		t.Fatal("leaked connection was not reclaimed")
+	}
+	fresh, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.ReleaseConnection(fresh)
+	// 持有方归还已被回收的连接不视为错误
+	if err := p.ReleaseConnection(conn); err != nil {
+		t.Fatal(err)
+	}
+	if stats := p.Stats(); stats.Leaks != 1 || stats.LeaksReclaimed != 1 || stats.InUseConnections != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestGenericConnectionPoolInvalidRelease(t *testing.T) {
+	cfg := newTestConfig(1)
+	cfg.DebugRelease = true
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", newFakeFactory(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	conn, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.ReleaseConnection(conn); err != nil {
+		t.Fatal(err)
+	}
+	err = p.ReleaseConnection(conn)
+	if !errors.Is(err, ErrDoubleRelease) {
+		t.Fatalf("expected ErrDoubleRelease, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "TestGenericConnectionPoolInvalidRelease") {
+		t.Fatalf("expected the first release stack in %q", err)
+	}
+	if err := p.ReleaseConnection(&fakeConn{}); !errors.Is(err, ErrUnknownConnection) {
+		t.Fatalf("expected ErrUnknownConnection, got %v", err)
+	}
+	// 重复归还不会让同一连接被借出两次
+	if _, err := p.GetConnection(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.GetConnection(context.Background()); !errors.Is(err, ErrAcquireTimeout) {
+		t.Fatalf("expected ErrAcquireTimeout, got %v", err)
+	}
+	p.ReleaseConnection(conn)
+
+	cfg.PanicOnInvalidRelease = true
+	defer func() {
+		if err, ok := recover().(error); !ok || !errors.Is(err, ErrUnknownConnection) {
+			t.Fatalf("expected panic with ErrUnknownConnection, got %v", err)
+		}
+	}()
+	p.ReleaseConnection(&fakeConn{})
+}
+
+func TestPooledConnReleaseAndDiscard(t *testing.T) {
+	factory := newFakeFactory()
+	cfg := newTestConfig(1)
+	discarded := make(chan error, 1)
+	cfg.Hooks.OnDiscard = []func(conn interface{}, err error){
+		func(conn interface{}, err error) {
+			discarded <- err
+		},
+	}
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := NewPool[*fakeConn](p)
+	defer pool.Close(context.Background())
+
+	before := time.Now()
+	h, err := pool.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.BorrowedAt().Before(before) {
+		t.Fatal("unexpected BorrowedAt")
+	}
+	// 重复 Release 不会重复归还
+	if err := h.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Release(); err != nil {
+		t.Fatal(err)
+	}
+
+	h, err = pool.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken := errors.New("broken pipe")
+	if err := h.Discard(broken); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if !factory.isClosed(h.Conn()) {
+		t.Fatal("discarded connection was not closed")
+	}
+	if err := <-discarded; err != broken {
+		t.Fatalf("unexpected discard error %v", err)
+	}
+	if stats := pool.Stats(); stats.Discarded != 1 || stats.InUseConnections != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	// 被销毁的连接由定时任务补充
+	h, err = pool.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Release()
+}
+
+func TestPooledConnStaleRelease(t *testing.T) {
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", newFakeFactory(), newTestConfig(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := NewPool[*fakeConn](p)
+	defer pool.Close(context.Background())
+
+	// 连接归还后被再次借出，旧句柄的重复归还不能让新持有方的连接被借给其他调用方
+	stale, err := pool.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := stale.Release(); err != nil {
+		t.Fatal(err)
+	}
+	current, err := pool.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if current.Conn() != stale.Conn() {
+		t.Fatal("expected the released connection to be lent again")
+	}
+	if err := stale.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if err := stale.Discard(errors.New("broken pipe")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.TryGetConnection(context.Background()); !errors.Is(err, ErrPoolExhausted) {
+		t.Fatalf("connection in use was lent again after a stale release: %v", err)
+	}
+	if stats := pool.Stats(); stats.InUseConnections != 1 || stats.Discarded != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+	if err := current.Release(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPoolDo(t *testing.T) {
+	factory := newFakeFactory()
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, newTestConfig(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := NewPool[*fakeConn](p)
+	defer pool.Close(context.Background())
+
+	// 业务错误归还连接，连接故障销毁连接
+	notFound := errors.New("not found")
+	if err := pool.Do(context.Background(), func(conn *fakeConn) error { return notFound }); err != notFound {
+		t.Fatalf("expected fn error, got %v", err)
+	}
+	// 单次调用超时或取消不销毁连接
+	var timedOut *fakeConn
+	err = pool.Do(context.Background(), func(conn *fakeConn) error {
+		timedOut = conn
+		return fmt.Errorf("query failed: %w", context.DeadlineExceeded)
+	})
+	if !errors.Is(err, context.DeadlineExceeded) || factory.isClosed(timedOut) {
+		t.Fatalf("timeout should not discard the connection, got %v", err)
+	}
+	var broken *fakeConn
+	err = pool.Do(context.Background(), func(conn *fakeConn) error {
+		broken = conn
+		return fmt.Errorf("query failed: %w", io.EOF)
+	})
+	if !errors.Is(err, io.EOF) || !factory.isClosed(broken) {
+		t.Fatalf("connection error should discard the connection, got %v", err)
+	}
+
+	// panic 时归还连接后继续 panic
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected panic to propagate")
+			}
+		}()
+		pool.Do(context.Background(), func(conn *fakeConn) error { panic("boom") })
+	}()
+	if stats := pool.Stats(); stats.InUseConnections != 0 || stats.Discarded != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	// 重试时换一个新连接
+	var used []*fakeConn
+	err = pool.DoWithRetry(context.Background(), 3, func(conn *fakeConn) error {
+		used = append(used, conn)
+		if len(used) == 1 {
+			return ErrConnectionUnhealthy
+		}
+		return nil
+	})
+	if err != nil || len(used) != 2 || used[0] == used[1] {
+		t.Fatalf("unexpected retry result: err=%v, used=%v", err, used)
+	}
+	attempts := 0
+	err = pool.DoWithRetry(context.Background(), 3, func(conn *fakeConn) error {
+		attempts++
+		return notFound
+	})
+	if err != notFound || attempts != 1 {
+		t.Fatalf("business errors should not be retried: err=%v, attempts=%d", err, attempts)
+	}
+	// attempts 不大于 0 时仍执行一次
+	attempts = 0
+	err = pool.DoWithRetry(context.Background(), 0, func(conn *fakeConn) error {
+		attempts++
+		return ErrConnectionUnhealthy
+	})
+	if !errors.Is(err, ErrConnectionUnhealthy) || attempts != 1 {
+		t.Fatalf("expected fn to run once with attempts=0: err=%v, attempts=%d", err, attempts)
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{errors.New("not found"), false},
+		{io.EOF, true},
+		{fmt.Errorf("query failed: %w", ErrConnectionUnhealthy), true},
+		{&net.OpError{Op: "read", Err: errors.New("i/o timeout")}, true},
+		{context.DeadlineExceeded, false},
+		{fmt.Errorf("query failed: %w", context.DeadlineExceeded), false},
+		{context.Canceled, false},
+		{fmt.Errorf("query failed: %w", context.Canceled), false},
+	}
+	for _, c := range cases {
+		if got := IsConnectionError(c.err); got != c.want {
+			t.Errorf("IsConnectionError(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
+
+func TestGenericConnectionPoolTryGetAndMaxWaiters(t *testing.T) {
+	cfg := newTestConfig(1)
+	cfg.Timeout = time.Second
+	cfg.MaxWaiters = 1
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", newFakeFactory(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	conn, err := p.TryGetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.TryGetConnection(context.Background()); !errors.Is(err, ErrPoolExhausted) {
+		t.Fatalf("expected ErrPoolExhausted, got %v", err)
+	}
+
+	// 第一个调用方排队等待，超过 MaxWaiters 的调用方立即失败
+	waited := make(chan error, 1)
+	go func() {
+		conn, err := p.GetConnection(context.Background())
+		if err == nil {
+			p.ReleaseConnection(conn)
+		}
+		waited <- err
+	}()
+	for p.Stats().Waiters != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	start := time.Now()
+	if _, err := p.GetConnection(context.Background()); !errors.Is(err, ErrPoolExhausted) {
+		t.Fatalf("expected ErrPoolExhausted, got %v", err)
+	}
+	if time.Since(start) > 100*time.Millisecond {
+		t.Fatal("caller over MaxWaiters should fail fast")
+	}
+
+	p.ReleaseConnection(conn)
+	if err := <-waited; err != nil {
+		t.Fatal(err)
+	}
+	if stats := p.Stats(); stats.Waiters != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestGenericConnectionPoolFIFOWaiters(t *testing.T) {
+	factory := newFakeFactory()
+	cfg := newTestConfig(1)
+	cfg.Timeout = time.Second
+	cfg.DisableTestWhileIdle = true
+	cfg.TestOnBorrow = true
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	conn, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 等待方依次排队，归还的连接按到达顺序交给等待方
+	const waiters = 5
+	order := make(chan int, waiters)
+	for i := 0; i < waiters; i++ {
+		go func(i int) {
+			conn, err := p.GetConnection(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			order <- i
+			p.ReleaseConnection(conn)
+		}(i)
+		for p.Stats().Waiters != i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	// 交给最早等待方的连接借出前检查失败，该等待方重新排在最前，等待后端恢复后新建的连接
+	factory.setBroken(conn)
+	factory.setDown(true)
+	p.ReleaseConnection(conn)
+	for p.Stats().HealthCheckFailures != 1 || p.Stats().Waiters != waiters {
+		time.Sleep(time.Millisecond)
+	}
+	factory.setDown(false)
+	for i := 0; i < waiters; i++ {
+		if got := <-order; got != i {
+			t.Fatalf("waiter %d was served before waiter %d", got, i)
+		}
+	}
+}
+
+// BenchmarkGenericConnectionPoolOversubscribed 10 倍超额订阅下获取连接的尾延迟
+func BenchmarkGenericConnectionPoolOversubscribed(b *testing.B) {
+	const (
+		connections = 10
+		goroutines  = 10 * connections
+	)
+	cfg := newTestConfig(connections)
+	cfg.Timeout = time.Minute
+	cfg.HealthCheckInterval = time.Minute
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", newFakeFactory(), cfg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	latencies := make([]time.Duration, b.N)
+	var next int64 = -1
+	var wg sync.WaitGroup
+	b.ResetTimer()
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := atomic.AddInt64(&next, 1)
+				if i >= int64(b.N) {
+					return
+				}
+				start := time.Now()
+				conn, err := p.GetConnection(context.Background())
+				if err != nil {
+					b.Error(err)
+					return
+				}
+				latencies[i] = time.Since(start)
+				time.Sleep(50 * time.Microsecond)
+				p.ReleaseConnection(conn)
+			}
+		}()
+	}
+	wg.Wait()
+	b.StopTimer()
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	percentile := func(q float64) float64 {
+		return float64(latencies[int(q*float64(len(latencies)-1))].Microseconds())
+	}
+	b.ReportMetric(percentile(0.5), "p50-µs")
+	b.ReportMetric(percentile(0.99), "p99-µs")
+	b.ReportMetric(percentile(1), "max-µs")
+}
+
+func TestGenericConnectionPoolPriority(t *testing.T) {
+	cfg := newTestConfig(1)
+	cfg.Timeout = time.Second
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", newFakeFactory(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	conn, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 高优先级等待方即使后到也先拿到连接
+	order := make(chan Priority, 3)
+	for i, priority := range []Priority{PriorityLow, PriorityNormal, PriorityHigh} {
+		go func(priority Priority) {
+			conn, err := p.GetConnection(WithPriority(context.Background(), priority))
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			order <- priority
+			p.ReleaseConnection(conn)
+		}(priority)
+		for p.Stats().Waiters != i+1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	p.ReleaseConnection(conn)
+	for _, expected := range []Priority{PriorityHigh, PriorityNormal, PriorityLow} {
+		if got := <-order; got != expected {
+			t.Fatalf("expected priority %d to be served, got %d", expected, got)
+		}
+	}
+}
+
+func TestGenericConnectionPoolReservedConnections(t *testing.T) {
+	cfg := newTestConfig(2)
+	cfg.ReservedConnections = 1
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", newFakeFactory(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	normal, err := p.TryGetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 预留的连接不借给普通调用方
+	if _, err := p.TryGetConnection(context.Background()); !errors.Is(err, ErrPoolExhausted) {
+		t.Fatalf("expected ErrPoolExhausted, got %v", err)
+	}
+	waited := make(chan error, 1)
+	go func() {
+		conn, err := p.GetConnection(context.Background())
+		if err == nil {
+			p.ReleaseConnection(conn)
+		}
+		waited <- err
+	}()
+	high, err := p.TryGetConnection(WithPriority(context.Background(), PriorityHigh))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.ReleaseConnection(high)
+
+	// 普通调用方归还后，等待中的普通调用方获得名额
+	p.ReleaseConnection(normal)
+	if err := <-waited; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGenericConnectionPoolMinIdle(t *testing.T) {
+	factory := newFakeFactory()
+	cfg := newTestConfig(3)
+	cfg.MinIdle = 1
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	// 启动时只创建 MinIdle 个连接
+	if stats := p.Stats(); stats.TotalConnections != 1 {
+		t.Fatalf("expected 1 connection after startup, got %+v", stats)
+	}
+
+	// 按需增长到 MaxConnections
+	conns := make([]*fakeConn, 0, 3)
+	for i := 0; i < 3; i++ {
+		conn, err := p.GetConnection(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		conns = append(conns, conn)
+	}
+	if stats := p.Stats(); stats.TotalConnections != 3 {
+		t.Fatalf("expected pool to grow to 3 connections, got %+v", stats)
+	}
+	if _, err := p.GetConnection(context.Background()); !errors.Is(err, ErrAcquireTimeout) {
+		t.Fatalf("expected ErrAcquireTimeout, got %v", err)
+	}
+	for _, conn := range conns {
+		p.ReleaseConnection(conn)
+	}
+
+	// 空闲连接被回收后，后台补充到 MinIdle
+	factory.setBroken(conns[0])
+	factory.setBroken(conns[1])
+	factory.setBroken(conns[2])
+	time.Sleep(100 * time.Millisecond)
+	if stats := p.Stats(); stats.IdleConnections != 1 || stats.TotalConnections != 1 {
+		t.Fatalf("expected MinIdle connections to be kept, got %+v", stats)
+	}
+}
+
+func TestGenericConnectionPoolMaxLifetime(t *testing.T) {
+	factory := newFakeFactory()
+	cfg := newTestConfig(2)
+	cfg.MaxLifetime = 50 * time.Millisecond
+	cfg.LifetimeJitter = 10 * time.Millisecond
+	cfg.CleanupInterval = 20 * time.Millisecond
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	borrowed, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	// 借出的连接过期后不会被关闭，空闲连接由定期清理关闭
+	if factory.isClosed(borrowed) {
+		t.Fatal("expected borrowed connection to stay open past MaxLifetime")
+	}
+	stats := p.Stats()
+	if stats.MaxLifetimeClosed == 0 {
+		t.Fatalf("expected idle connection to be retired by cleanup, got %+v", stats)
+	}
+
+	// 过期的连接在归还时关闭
+	closed := stats.MaxLifetimeClosed
+	if err := p.ReleaseConnection(borrowed); err != nil {
+		t.Fatal(err)
+	}
+	if !factory.isClosed(borrowed) {
+		t.Fatal("expected expired connection to be closed on release")
+	}
+	if stats := p.Stats(); stats.MaxLifetimeClosed != closed+1 {
+		t.Fatalf("expected release to retire expired connection, got %+v", stats)
+	}
+}
+
+func TestGenericConnectionPoolIdleTime(t *testing.T) {
+	factory := newFakeFactory()
+	cfg := newTestConfig(2)
+	cfg.MinIdle = 0
+	cfg.MaxIdleTime = 50 * time.Millisecond
+	cfg.CleanupInterval = 10 * time.Millisecond
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	borrowed, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 没有空闲连接时按需创建的连接从未借出
+	if _, err := p.TryGetConnection(context.Background()); !errors.Is(err, ErrPoolExhausted) {
+		t.Fatalf("expected ErrPoolExhausted, got %v", err)
+	}
+	for p.Stats().IdleConnections != 1 {
+		time.Sleep(time.Millisecond)
+	}
+	p.mu.Lock()
+	neverBorrowed := p.idle[0]
+	p.mu.Unlock()
+	time.Sleep(150 * time.Millisecond)
+
+	// 从未借出的空闲连接超时后被回收，借出的连接不会被回收
+	if !factory.isClosed(neverBorrowed) {
+		t.Fatal("expected never-borrowed idle connection to be reclaimed")
+	}
+	if factory.isClosed(borrowed) {
+		t.Fatal("expected borrowed connection to stay open past MaxIdleTime")
+	}
+	if err := p.ReleaseConnection(borrowed); err != nil {
+		t.Fatal(err)
+	}
+
+	// 空闲时间从归还时开始计算
+	p.mu.Lock()
+	meta := p.conns[borrowed]
+	if meta == nil || meta.borrowed || meta.lastReleased.Before(meta.lastAcquired) {
+		p.mu.Unlock()
+		t.Fatalf("expected released connection to be idle, got %+v", meta)
+	}
+	p.mu.Unlock()
+	if factory.isClosed(borrowed) {
+		t.Fatal("expected released connection to stay open right after release")
+	}
+	time.Sleep(150 * time.Millisecond)
+	if !factory.isClosed(borrowed) {
+		t.Fatal("expected released connection to be reclaimed after MaxIdleTime")
+	}
+}
+
+func TestGenericConnectionPoolReset(t *testing.T) {
+	factory := newResetFactory()
+	cfg := newTestConfig(1)
+	cfg.Timeout = time.Second
+	closed := make(chan config.CloseReason, 1)
+	cfg.Hooks.OnClose = []func(conn interface{}, reason config.CloseReason){
+		func(conn interface{}, reason config.CloseReason) {
+			closed <- reason
+		},
+	}
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 重置成功的连接放回空闲连接
+	conn, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.ReleaseConnection(conn); err != nil {
+		t.Fatal(err)
+	}
+	if n := factory.resetCount(); n != 1 {
+		t.Fatalf("expected 1 reset, got %d", n)
+	}
+	if stats := p.Stats(); stats.IdleConnections != 1 || factory.isClosed(conn) {
+		t.Fatalf("reset connection was not returned to idle: %+v", stats)
+	}
+
+	// 重置失败的连接被关闭
+	conn, err = p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory.setResetFail(conn)
+	if err := p.ReleaseConnection(conn); err != nil {
+		t.Fatal(err)
+	}
+	if reason := <-closed; reason != config.CloseReasonResetFailed {
+		t.Fatalf("expected CloseReasonResetFailed, got %s", reason)
+	}
+	if !factory.isClosed(conn) {
+		t.Fatal("expected connection to be closed after a failed reset")
+	}
+
+	// 重置期间 Close 取消重置，不必等待后端超时
+	conn, err = p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory.setResetHang(true)
+	resets := factory.resetCount()
+	go p.ReleaseConnection(conn)
+	for factory.resetCount() == resets {
+		time.Sleep(time.Millisecond)
+	}
+	start := time.Now()
+	if err := p.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("Close took %s with a hung reset", elapsed)
+	}
+	if reason := <-closed; reason != config.CloseReasonPoolClosed {
+		t.Fatalf("expected CloseReasonPoolClosed, got %s", reason)
+	}
+}
+
+func TestGenericConnectionPoolTestOnBorrowAndReturn(t *testing.T) {
+	factory := newFakeFactory()
+	cfg := newTestConfig(2)
+	cfg.DisableTestWhileIdle = true
+	cfg.TestOnBorrow = true
+	cfg.TestOnReturn = true
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	// 借出前检查失败的连接被关闭，换成另一个连接
+	conn, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.ReleaseConnection(conn)
+	factory.setBroken(conn)
+	fresh, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh == conn || !factory.isClosed(conn) {
+		t.Fatal("expected broken connection to be replaced on borrow")
+	}
+
+	// 归还时检查失败的连接被关闭
+	factory.setBroken(fresh)
+	if err := p.ReleaseConnection(fresh); err != nil {
+		t.Fatal(err)
+	}
+	if !factory.isClosed(fresh) {
+		t.Fatal("expected broken connection to be closed on return")
+	}
+	if stats := p.Stats(); stats.HealthCheckFailures != 2 {
+		t.Fatalf("expected 2 health check failures, got %+v", stats)
+	}
+
+	// 没有空闲连接时等待新建的连接
+	replaced, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.ReleaseConnection(replaced)
+}
+
+func TestGenericConnectionPoolTestOnBorrowCallerCancel(t *testing.T) {
+	factory := newFakeFactory()
+	cfg := newTestConfig(1)
+	cfg.DisableTestWhileIdle = true
+	cfg.TestOnBorrow = true
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	// 借出前检查期间调用方取消，连接放回空闲连接，不计为检查失败
+	factory.setHang(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err := p.GetConnection(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	factory.setHang(false)
+	if stats := p.Stats(); stats.HealthCheckFailures != 0 || stats.TotalConnections != 1 || stats.IdleConnections != 1 {
+		t.Fatalf("healthy connection was removed after the caller cancelled: %+v", stats)
+	}
+
+	conn, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if factory.isClosed(conn) {
+		t.Fatal("got a closed connection")
+	}
+	p.ReleaseConnection(conn)
+}
+
+func TestGenericConnectionPoolSkipValidationWithin(t *testing.T) {
+	factory := newFakeFactory()
+	cfg := newTestConfig(1)
+	cfg.DisableTestWhileIdle = true
+	cfg.TestOnBorrow = true
+	cfg.TestOnReturn = true
+	cfg.SkipValidationWithin = time.Minute
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	// 最近使用过的连接跳过借出与归还检查
+	conn, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory.setBroken(conn)
+	p.ReleaseConnection(conn)
+	again, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != conn || factory.isClosed(conn) {
+		t.Fatal("expected recently used connection to skip validation")
+	}
+	p.ReleaseConnection(again)
+	if stats := p.Stats(); stats.HealthCheckFailures != 0 {
+		t.Fatalf("expected no health check failures, got %+v", stats)
+	}
+}
+
+func TestGenericConnectionPoolDialBackoff(t *testing.T) {
+	factory := newFakeFactory()
+	factory.setDown(true)
+	cfg := newTestConfig(2)
+	cfg.Lazy = true
+	cfg.DialBackoff = 100 * time.Millisecond
+	cfg.DialBackoffMax = time.Second
+	cfg.DialBackoffJitter = 0.5
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	// 退避期间不创建连接，获取连接也不会触发创建
+	for i := 0; i < 10; i++ {
+		if _, err := p.TryGetConnection(context.Background()); !errors.Is(err, ErrPoolExhausted) {
+			t.Fatalf("expected ErrPoolExhausted, got %v", err)
+		}
+	}
+	time.Sleep(300 * time.Millisecond)
+	if attempts := factory.dialAttempts(); attempts > 4 {
+		t.Fatalf("expected dial attempts to back off, got %d", attempts)
+	}
+	stats := p.Stats()
+	if stats.DialFailures == 0 || stats.LastDialError == nil {
+		t.Fatalf("expected dial failures to be recorded, got %+v", stats)
+	}
+
+	// 后端恢复后退避结束时补充连接，并清零连续失败次数
+	factory.setDown(false)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	conn, err := p.GetConnection(ctx)
+	for err != nil && ctx.Err() == nil {
+		conn, err = p.GetConnection(ctx)
+	}
+	if err != nil {
+		t.Fatalf("pool did not recover: %v", err)
+	}
+	p.ReleaseConnection(conn)
+	if stats := p.Stats(); stats.DialFailures != 0 {
+		t.Fatalf("expected dial failures to be reset, got %+v", stats)
+	}
+}
+
+func TestGenericConnectionPoolReclaimKeepsMinIdle(t *testing.T) {
+	factory := newFakeFactory()
+	cfg := newTestConfig(2)
+	cfg.MinIdle = 1
+	cfg.MaxIdleTime = 30 * time.Millisecond
+	cfg.CleanupInterval = 10 * time.Millisecond
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", factory, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	first, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := p.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.ReleaseConnection(first)
+	p.ReleaseConnection(second)
+
+	// 空闲超时的连接只回收到 MinIdle 个，保留的连接不会被反复关闭与重建
+	time.Sleep(150 * time.Millisecond)
+	stats := p.Stats()
+	if stats.IdleConnections != 1 || stats.IdleClosed != 1 {
+		t.Fatalf("expected one idle connection to be kept, got %+v", stats)
+	}
+	if attempts := factory.dialAttempts(); attempts != 2 {
+		t.Fatalf("expected no redials, got %d dial attempts", attempts)
+	}
+}
@@ -0,0 +1,50 @@
+package connection_pool
+
+import (
+	"context"
+	"github.com/practice/connection-pool/pkg/pool/config"
+)
+
+// runOnCreate 依次调用 OnCreate 钩子，遇到错误即返回
+func (p *GenericConnectionPool[T]) runOnCreate(ctx context.Context, conn T) error {
+	for _, hook := range p.config.Hooks.OnCreate {
+		if err := hook(ctx, conn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runOnAcquire 依次调用 OnAcquire 钩子，遇到错误即返回
+func (p *GenericConnectionPool[T]) runOnAcquire(ctx context.Context, conn T) error {
+	for _, hook := range p.config.Hooks.OnAcquire {
+		if err := hook(ctx, conn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *GenericConnectionPool[T]) runOnRelease(conn T) {
+	for _, hook := range p.config.Hooks.OnRelease {
+		hook(conn)
+	}
+}
+
+func (p *GenericConnectionPool[T]) runOnClose(conn T, reason config.CloseReason) {
+	for _, hook := range p.config.Hooks.OnClose {
+		hook(conn, reason)
+	}
+}
+
+func (p *GenericConnectionPool[T]) runOnDiscard(conn T, err error) {
+	for _, hook := range p.config.Hooks.OnDiscard {
+		hook(conn, err)
+	}
+}
+
+func (p *GenericConnectionPool[T]) runOnHealthFail(conn T, err error) {
+	for _, hook := range p.config.Hooks.OnHealthFail {
+		hook(conn, err)
+	}
+}
@@ -1,50 +1,135 @@
 package connection_pool
 
 import (
-	"sync"
+	"context"
+	"fmt"
+	"time"
 )
 
-// IConnectionPool 接口定义连接池方法
-type IConnectionPool interface {
-	// GetConnection 获取连接实例
-	GetConnection() (interface{}, error)
-	// ReleaseConnection 释放连接实例
-	ReleaseConnection(interface{})
-	// Close 关闭连接池
-	Close()
+// IConnectionPool 接口定义连接池方法，T 为连接实例类型，实现需保证各方法可并发调用
+type IConnectionPool[T any] interface {
+	// GetConnection 获取连接实例，ctx 取消、超时或连接池关闭后放弃等待
+	GetConnection(ctx context.Context) (T, error)
+	// TryGetConnection 获取连接实例，没有空闲连接时立即返回 ErrPoolExhausted
+	TryGetConnection(ctx context.Context) (T, error)
+	// ReleaseConnection 释放连接实例，连接池关闭后释放的连接会被直接关闭，
+	// 重复释放或释放不属于该连接池的连接时返回 ErrDoubleRelease 或 ErrUnknownConnection。
+	// 连接按值识别，连接被再次借出后，之前持有方的重复释放无法与新持有方区分，会被当作新持有方的释放接受，
+	// 需要防止时通过 Pool 借出的 PooledConn 句柄释放
+	ReleaseConnection(T) error
+	// DiscardConnection 归还已损坏的连接，连接池关闭该连接而不是放回，err 为连接损坏的原因，
+	// 与 ReleaseConnection 一样会拒绝重复归还与不属于该连接池的连接
+	DiscardConnection(T, error) error
+	// Close 关闭连接池，在 ctx 截止前等待借出的连接归还，随后关闭全部连接，可重复调用
+	Close(ctx context.Context) error
+	// Stats 返回连接池统计信息快照
+	Stats() Stats
 }
 
-// ConnectionPool 连接池对象
-type ConnectionPool struct {
+// Pool 类型安全的连接池对象，借出的连接以 PooledConn 句柄返回，获取、归还与关闭可并发执行
+type Pool[T any] struct {
 	// ConnectionPool 连接池接口对象
-	ConnectionPool IConnectionPool
-	lock           sync.Mutex
+	ConnectionPool IConnectionPool[T]
+	// IsConnectionError 判断 Do 中 fn 返回的错误是否为连接故障，为 nil 时使用包级 IsConnectionError
+	IsConnectionError func(err error) bool
 }
 
-func NewConnectionPool(connectionPool IConnectionPool) *ConnectionPool {
-	return &ConnectionPool{
+// ConnectionPool 非泛型连接池对象，连接实例以 interface{} 形式返回，
+// 调用方需要自行断言类型
+type ConnectionPool = Pool[interface{}]
+
+// NewPool 创建类型安全的连接池对象
+func NewPool[T any](connectionPool IConnectionPool[T]) *Pool[T] {
+	return &Pool[T]{
 		ConnectionPool: connectionPool,
-		lock:           sync.Mutex{},
 	}
 }
 
+// NewConnectionPool 创建非泛型连接池对象，内部适配为 interface{} 形式
+func NewConnectionPool[T any](connectionPool IConnectionPool[T]) *ConnectionPool {
+	return NewPool[interface{}](&untypedConnectionPool[T]{pool: connectionPool})
+}
+
+// GetConnection 获取连接句柄，使用完毕后调用其 Release 归还，连接损坏时调用 Discard，
+// 连接池关闭时正在等待的调用方会立即返回
+func (c *Pool[T]) GetConnection(ctx context.Context) (*PooledConn[T], error) {
+	return c.newPooledConn(c.ConnectionPool.GetConnection(ctx))
+}
+
+// TryGetConnection 获取连接句柄，没有空闲连接时立即返回 ErrPoolExhausted
+func (c *Pool[T]) TryGetConnection(ctx context.Context) (*PooledConn[T], error) {
+	return c.newPooledConn(c.ConnectionPool.TryGetConnection(ctx))
+}
+
+// newPooledConn 将借出的连接包装为句柄
+func (c *Pool[T]) newPooledConn(conn T, err error) (*PooledConn[T], error) {
+	if err != nil {
+		return nil, err
+	}
+	return &PooledConn[T]{
+		conn:       conn,
+		pool:       c.ConnectionPool,
+		borrowedAt: time.Now(),
+	}, nil
+}
+
+// Close 关闭连接池，在 ctx 截止前等待借出的连接归还，可重复调用
+func (c *Pool[T]) Close(ctx context.Context) error {
+	return c.ConnectionPool.Close(ctx)
+}
+
+// Stats 返回连接池统计信息快照
+func (c *Pool[T]) Stats() Stats {
+	return c.ConnectionPool.Stats()
+}
+
+// untypedConnectionPool 将 IConnectionPool[T] 适配为 IConnectionPool[interface{}]
+type untypedConnectionPool[T any] struct {
+	pool IConnectionPool[T]
+}
+
 // GetConnection 获取连接实例
-func (c *ConnectionPool) GetConnection() (interface{}, error) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	return c.ConnectionPool.GetConnection()
+func (u *untypedConnectionPool[T]) GetConnection(ctx context.Context) (interface{}, error) {
+	conn, err := u.pool.GetConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
 }
 
-// ReleaseConnection 释放连接实例
-func (c *ConnectionPool) ReleaseConnection(connection interface{}) {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	c.ConnectionPool.ReleaseConnection(connection)
+// TryGetConnection 获取连接实例，没有空闲连接时立即返回 ErrPoolExhausted
+func (u *untypedConnectionPool[T]) TryGetConnection(ctx context.Context) (interface{}, error) {
+	conn, err := u.pool.TryGetConnection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
+
+// ReleaseConnection 释放连接实例，连接类型与底层连接池不一致时返回 ErrUnknownConnection
+func (u *untypedConnectionPool[T]) ReleaseConnection(connection interface{}) error {
+	conn, ok := connection.(T)
+	if !ok {
+		return fmt.Errorf("failed to release connection of type %T: %w", connection, ErrUnknownConnection)
+	}
+	return u.pool.ReleaseConnection(conn)
+}
+
+// DiscardConnection 归还已损坏的连接，连接类型与底层连接池不一致时返回 ErrUnknownConnection
+func (u *untypedConnectionPool[T]) DiscardConnection(connection interface{}, err error) error {
+	conn, ok := connection.(T)
+	if !ok {
+		return fmt.Errorf("failed to discard connection of type %T: %w", connection, ErrUnknownConnection)
+	}
+	return u.pool.DiscardConnection(conn, err)
 }
 
 // Close 关闭连接池
-func (c *ConnectionPool) Close() {
-	c.lock.Lock()
-	defer c.lock.Unlock()
-	c.ConnectionPool.Close()
+func (u *untypedConnectionPool[T]) Close(ctx context.Context) error {
+	return u.pool.Close(ctx)
+}
+
+// Stats 返回连接池统计信息快照
+func (u *untypedConnectionPool[T]) Stats() Stats {
+	return u.pool.Stats()
 }
@@ -3,11 +3,13 @@ package connection_pool
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	redis2 "github.com/go-redis/redis/v8"
 	"github.com/practice/connection-pool/pkg/pool/config"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"log"
+	"sync"
 	"testing"
 	"time"
 )
@@ -24,19 +26,19 @@ func TestConnectionPoll(t *testing.T) {
 
 	//// 创建 MySQL 连接池
 	mysqlPool := NewConnectionPool(MysqlMode("mysql", "root:1234567@tcp(127.0.0.1:3306)/testdb", cfg))
-	defer mysqlPool.Close()
+	defer mysqlPool.Close(context.Background())
 
 	// 从 MySQL 连接池获取连接
-	mysqlConn, err := mysqlPool.GetConnection()
+	mysqlConn, err := mysqlPool.GetConnection(context.Background())
 	if err != nil {
 	log.Fatal("Failed to get MySQL connection:", err)
 	}
 
-	mysqlDB := mysqlConn.(*sql.DB)
-	defer mysqlPool.ReleaseConnection(mysqlConn)
+	mysqlDB := mysqlConn.Conn().(*sql.Conn)
+	defer mysqlConn.Release()
 
 	// 执行数据库查询操作
-	rows, err := mysqlDB.Query("SELECT * FROM example")
+	rows, err := mysqlDB.QueryContext(context.Background(), "SELECT * FROM example")
 	if err != nil {
 	log.Fatal("Failed to execute MySQL query:", err)
 	}
@@ -45,15 +47,15 @@ func TestConnectionPoll(t *testing.T) {
 	//
 	// 创建 Redis 连接池
 	redisPool := NewConnectionPool(RedisMode("127.0.0.1:6379", "", cfg))
-	defer redisPool.Close()
+	defer redisPool.Close(context.Background())
 
 	// 从 Redis 连接池获取连接
-	redisConn, err := redisPool.GetConnection()
+	redisConn, err := redisPool.GetConnection(context.Background())
 	if err != nil {
 		log.Fatal("Failed to get Redis connection:", err)
 	}
-	redisClient := redisConn.(*redis2.Client)
-	defer redisPool.ReleaseConnection(redisConn)
+	redisClient := redisConn.Conn().(*redis2.Client)
+	defer redisConn.Release()
 
 	// 执行 Redis 操作
 	err = redisClient.Set(context.Background(), "my-key", "my-value", 0).Err()
@@ -74,15 +76,15 @@ func TestConnectionPoll(t *testing.T) {
 	}
 	// 创建 Redis 连接池
 	etcdPool := NewConnectionPool(EtcdMode(cccddd, cfg))
-	defer etcdPool.Close()
+	defer etcdPool.Close(context.Background())
 
 	// 从 Redis 连接池获取连接
-	etcdConn, err := etcdPool.GetConnection()
+	etcdConn, err := etcdPool.GetConnection(context.Background())
 	if err != nil {
 		log.Fatal("Failed to get Redis connection:", err)
 	}
-	etcdClient := etcdConn.(*clientv3.Client)
-	defer etcdPool.ReleaseConnection(etcdConn)
+	etcdClient := etcdConn.Conn().(*clientv3.Client)
+	defer etcdConn.Release()
 
 	// 执行 Redis 操作
 	_, err = etcdClient.Put(context.Background(), "aaa", "aaa")
@@ -98,3 +100,99 @@ func TestConnectionPoll(t *testing.T) {
 	fmt.Println(rr.Kvs[0].String())
 
 }
+
+func TestPoolConcurrentAcquireRelease(t *testing.T) {
+	const (
+		connections = 4
+		goroutines  = 64
+		iterations  = 50
+	)
+	cfg := newTestConfig(connections)
+	cfg.Timeout = 5 * time.Second
+	// 从一个连接开始，压力下按需增长到 MaxConnections
+	cfg.MinIdle = 1
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", newFakeFactory(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := NewPool[*fakeConn](p)
+	defer pool.Close(context.Background())
+
+	var borrowed sync.Map
+	var wg sync.WaitGroup
+	errCh := make(chan error, goroutines)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				conn, err := pool.GetConnection(context.Background())
+				if err != nil {
+					errCh <- err
+					return
+				}
+				if _, loaded := borrowed.LoadOrStore(conn.Conn(), struct{}{}); loaded {
+					errCh <- fmt.Errorf("connection %d borrowed twice", conn.Conn().id)
+					return
+				}
+				time.Sleep(100 * time.Microsecond)
+				borrowed.Delete(conn.Conn())
+				conn.Release()
+			}
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+	for err := range errCh {
+		t.Fatal(err)
+	}
+	if stats := pool.Stats(); stats.InUseConnections != 0 || stats.TotalConnections > connections {
+		t.Fatalf("unexpected stats after stress test: %+v", stats)
+	}
+}
+
+func TestPoolCloseWakesWaiters(t *testing.T) {
+	cfg := newTestConfig(1)
+	cfg.Timeout = 5 * time.Second
+	p, err := NewGenericConnectionPool[*fakeConn]("fake", newFakeFactory(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := NewPool[*fakeConn](p)
+	conn, err := pool.GetConnection(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := pool.GetConnection(context.Background())
+		errCh <- err
+	}()
+	for pool.Stats().Waiters != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// Close 等待借出的连接归还，等待中的调用方立即返回
+	closed := make(chan error, 1)
+	go func() {
+		closed <- pool.Close(context.Background())
+	}()
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, ErrPoolClosed) {
+			t.Fatalf("expected ErrPoolClosed, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter was not woken up by Close")
+	}
+
+	// 关闭后释放连接不应 panic
+	conn.Release()
+	if err := <-closed; err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,67 @@
+package connection_pool
+
+import (
+	"github.com/practice/connection-pool/pkg/pool/config"
+	"log"
+	"time"
+)
+
+// leak 一次泄漏报告
+type leak[T comparable] struct {
+	conn  T
+	held  time.Duration
+	stack []byte
+}
+
+// startLeakDetectionTask 启动定时任务来定期检查借出过久的连接
+func (p *GenericConnectionPool[T]) startLeakDetectionTask() {
+	defer p.wg.Done()
+	ticker := time.NewTicker(p.config.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.detectLeaks()
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// detectLeaks 报告借出超过 LeakThreshold 的连接，每次借出只报告一次，
+// 开启 LeakReclaim 时强制回收该连接，报告在释放锁后进行
+func (p *GenericConnectionPool[T]) detectLeaks() {
+	p.mu.Lock()
+	var leaks []leak[T]
+	now := time.Now()
+	for conn, b := range p.borrowed {
+		held := now.Sub(b.at)
+		if b.leaked || b.released || held <= p.config.LeakThreshold {
+			continue
+		}
+		b.leaked = true
+		p.leaks++
+		leaks = append(leaks, leak[T]{conn: conn, held: held, stack: b.stack})
+
+		if p.config.LeakReclaim {
+			p.endBorrow(conn)
+			p.reclaimed[conn] = struct{}{}
+			if _, alive := p.conns[conn]; alive {
+				p.removeConnection(conn, config.CloseReasonLeaked)
+			}
+			p.leaksReclaimed++
+		}
+	}
+	p.unlock()
+
+	for _, l := range leaks {
+		if len(p.config.Hooks.OnLeak) == 0 {
+			log.Printf("%s connection held for %s without release, acquired at:\n%s", p.name, l.held, l.stack)
+			continue
+		}
+		for _, hook := range p.config.Hooks.OnLeak {
+			hook(l.conn, l.held, l.stack)
+		}
+	}
+}
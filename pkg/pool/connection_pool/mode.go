@@ -1,6 +1,9 @@
 package connection_pool
 
 import (
+	"database/sql"
+	"fmt"
+	redis2 "github.com/go-redis/redis/v8"
 	"github.com/practice/connection-pool/pkg/pool/config"
 	"github.com/practice/connection-pool/pkg/pool/etcd"
 	"github.com/practice/connection-pool/pkg/pool/mysql"
@@ -9,24 +12,67 @@ import (
 	"log"
 )
 
-// MysqlMode mysql模式
-func MysqlMode(driver, dsn string, cfg *config.ConnectionConfig) IConnectionPool {
-	c, err := mysql.NewMySQLConnectionPool(driver, dsn, cfg)
+// NewMysqlPool 创建 mysql 连接池，MySQL 不可用时返回错误；
+// cfg.Lazy 为 true 时以降级状态启动，后端恢复后自动建立连接
+func NewMysqlPool(driver, dsn string, cfg *config.ConnectionConfig) (IConnectionPool[*sql.Conn], error) {
+	factory, err := mysql.NewMySQLFactory(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MySQL connection pool: %w", err)
+	}
+	c, err := NewGenericConnectionPool[*sql.Conn]("MySQL", factory, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewRedisPool 创建 redis 连接池，Redis 不可用时返回错误；
+// cfg.Lazy 为 true 时以降级状态启动，后端恢复后自动建立连接
+func NewRedisPool(addr, password string, cfg *config.ConnectionConfig) (IConnectionPool[*redis2.Client], error) {
+	c, err := NewGenericConnectionPool[*redis2.Client]("Redis", redis.NewRedisFactory(addr, password), cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewEtcdPool 创建 etcd 连接池，ETCD 不可用时返回错误；
+// cfg.Lazy 为 true 时以降级状态启动，后端恢复后自动建立连接
+func NewEtcdPool(etcdConfig clientv3.Config, cfg *config.ConnectionConfig) (IConnectionPool[*clientv3.Client], error) {
+	c, err := NewGenericConnectionPool[*clientv3.Client]("ETCD", etcd.NewETCDFactory(etcdConfig), cfg)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// MysqlMode mysql模式，创建失败时直接退出进程
+//
+// Deprecated: 使用 NewMysqlPool，由调用方处理错误
+func MysqlMode(driver, dsn string, cfg *config.ConnectionConfig) IConnectionPool[*sql.Conn] {
+	c, err := NewMysqlPool(driver, dsn, cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
 	return c
 }
 
-// RedisMode redis模式
-func RedisMode(addr, password string, cfg *config.ConnectionConfig) IConnectionPool {
-	c := redis.NewRedisConnectionPool(addr, password, cfg)
+// RedisMode redis模式，创建失败时直接退出进程
+//
+// Deprecated: 使用 NewRedisPool，由调用方处理错误
+func RedisMode(addr, password string, cfg *config.ConnectionConfig) IConnectionPool[*redis2.Client] {
+	c, err := NewRedisPool(addr, password, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
 	return c
 }
 
-// EtcdMode etcd模式
-func EtcdMode(etcdConfig clientv3.Config, cfg *config.ConnectionConfig) IConnectionPool {
-	c, err := etcd.NewETCDConnectionPool(etcdConfig, cfg)
+// EtcdMode etcd模式，创建失败时直接退出进程
+//
+// Deprecated: 使用 NewEtcdPool，由调用方处理错误
+func EtcdMode(etcdConfig clientv3.Config, cfg *config.ConnectionConfig) IConnectionPool[*clientv3.Client] {
+	c, err := NewEtcdPool(etcdConfig, cfg)
 	if err != nil {
 		log.Fatal(err)
 	}
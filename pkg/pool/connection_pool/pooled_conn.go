@@ -0,0 +1,43 @@
+package connection_pool
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// PooledConn 从 Pool 借出的连接句柄，通过 Release 归还或 Discard 销毁，
+// 两者只有第一次调用生效，之后的调用直接返回 nil，可放心 defer Release。
+// 连接被再次借出后，旧句柄的重复归还不会影响新的持有方
+type PooledConn[T any] struct {
+	conn       T
+	pool       IConnectionPool[T]
+	borrowedAt time.Time
+	// done 是否已归还或销毁
+	done atomic.Bool
+}
+
+// Conn 返回底层连接实例，归还或销毁后不能再使用
+func (c *PooledConn[T]) Conn() T {
+	return c.conn
+}
+
+// BorrowedAt 返回借出时间
+func (c *PooledConn[T]) BorrowedAt() time.Time {
+	return c.borrowedAt
+}
+
+// Release 将连接归还连接池
+func (c *PooledConn[T]) Release() error {
+	if !c.done.CompareAndSwap(false, true) {
+		return nil
+	}
+	return c.pool.ReleaseConnection(c.conn)
+}
+
+// Discard 标记连接已损坏，连接池关闭该连接而不是放回，err 为连接损坏的原因
+func (c *PooledConn[T]) Discard(err error) error {
+	if !c.done.CompareAndSwap(false, true) {
+		return nil
+	}
+	return c.pool.DiscardConnection(c.conn, err)
+}
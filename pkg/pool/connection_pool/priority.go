@@ -0,0 +1,31 @@
+package connection_pool
+
+import "context"
+
+// Priority 获取连接的优先级，连接池耗尽时归还的连接优先交给优先级高的等待方，
+// 同一优先级按到达顺序
+type Priority int
+
+const (
+	// PriorityLow 低优先级，如批处理任务
+	PriorityLow Priority = -1
+	// PriorityNormal 默认优先级
+	PriorityNormal Priority = 0
+	// PriorityHigh 高优先级，如管理与健康检查接口，可使用 ReservedConnections 预留的连接
+	PriorityHigh Priority = 1
+)
+
+type priorityKey struct{}
+
+// WithPriority 返回带有获取连接优先级的 ctx，传给 GetConnection 后生效
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// PriorityFromContext 返回 ctx 中的获取连接优先级，未设置时为 PriorityNormal
+func PriorityFromContext(ctx context.Context) Priority {
+	if priority, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return priority
+	}
+	return PriorityNormal
+}
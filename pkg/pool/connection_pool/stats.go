@@ -0,0 +1,71 @@
+package connection_pool
+
+import "time"
+
+// WaitBuckets 获取连接等待时间分布的桶上界，创建连接池时复制，修改只影响之后创建的连接池
+var WaitBuckets = []time.Duration{
+	time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+}
+
+// WaitBucket 等待时间分布中的一个桶
+type WaitBucket struct {
+	// UpperBound 桶上界
+	UpperBound time.Duration
+	// Count 等待时间不超过 UpperBound 的累计获取次数
+	Count int64
+}
+
+// Stats 连接池统计信息快照
+type Stats struct {
+	// TotalConnections 当前连接总数，等于空闲连接数与借出连接数之和
+	TotalConnections int
+	// IdleConnections 空闲连接数
+	IdleConnections int
+	// InUseConnections 借出尚未归还的连接数
+	InUseConnections int
+	// CreatingConnections 正在创建的连接数
+	CreatingConnections int
+	// Waiters 正在等待空闲连接的调用方数量
+	Waiters int
+
+	// AcquireCount 获取连接的累计次数，包括失败的获取
+	AcquireCount int64
+	// WaitCount 获取连接时需要等待的累计次数
+	WaitCount int64
+	// WaitDuration 获取连接时累计等待时间
+	WaitDuration time.Duration
+	// WaitHistogram 获取连接等待时间的累计分布，按创建连接池时的 WaitBuckets 升序排列，
+	// 超出最大上界的次数为 AcquireCount 减去最后一个桶的 Count
+	WaitHistogram []WaitBucket
+	// Timeouts 获取连接超时的累计次数
+	Timeouts int64
+	// HealthCheckFailures 健康检查失败的累计次数
+	HealthCheckFailures int64
+	// IdleClosed 因空闲超时被回收的累计连接数
+	IdleClosed int64
+	// MaxLifetimeClosed 因超过最长存活时间被关闭的累计连接数
+	MaxLifetimeClosed int64
+	// Refills 定时任务补充的累计连接数
+	Refills int64
+	// Discarded 持有方报告损坏而被关闭的累计连接数
+	Discarded int64
+	// Leaks 借出超过 LeakThreshold 未归还的累计连接数
+	Leaks int64
+	// LeaksReclaimed 因泄漏被强制回收的累计连接数
+	LeaksReclaimed int64
+
+	// DialFailures 连续创建连接失败的次数，创建成功后清零
+	DialFailures int
+	// LastDialError 最近一次创建连接失败的错误，从未失败时为 nil
+	LastDialError error
+}
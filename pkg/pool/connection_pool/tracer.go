@@ -0,0 +1,19 @@
+package connection_pool
+
+import (
+	"context"
+	"time"
+)
+
+// noopTracer 未配置 Tracer 时使用的空实现
+type noopTracer struct{}
+
+func (noopTracer) StartAcquire(ctx context.Context, pool string) (context.Context, func(wait time.Duration, err error)) {
+	return ctx, func(time.Duration, error) {}
+}
+
+func (noopTracer) Release(ctx context.Context, pool string, borrowed time.Duration) {}
+
+func (noopTracer) StartHealthCheck(ctx context.Context, pool string) (context.Context, func(checked, failed int)) {
+	return ctx, func(int, int) {}
+}
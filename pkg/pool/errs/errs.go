@@ -0,0 +1,21 @@
+package errs
+
+import "errors"
+
+// 连接池通用错误，各后端返回时会附带后端信息进行包装，调用方可通过 errors.Is 判断
+var (
+	// ErrPoolClosed 连接池已关闭
+	ErrPoolClosed = errors.New("connection pool is closed")
+	// ErrAcquireTimeout 在 Timeout 内没有获取到连接
+	ErrAcquireTimeout = errors.New("timeout waiting for connection")
+	// ErrPoolExhausted 连接池中没有可用连接，且不再等待
+	ErrPoolExhausted = errors.New("connection pool exhausted")
+	// ErrConnectionUnhealthy 连接健康检查失败
+	ErrConnectionUnhealthy = errors.New("connection is unhealthy")
+	// ErrInvalidConfig 连接池配置不合法
+	ErrInvalidConfig = errors.New("invalid connection pool config")
+	// ErrDoubleRelease 连接已归还过，不能重复归还
+	ErrDoubleRelease = errors.New("connection released more than once")
+	// ErrUnknownConnection 连接不是从该连接池借出的
+	ErrUnknownConnection = errors.New("connection does not belong to this pool")
+)
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"github.com/practice/connection-pool/pkg/pool/connection_pool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const namespace = "connection_pool"
+
+// StatsProvider 可提供统计信息的连接池，IConnectionPool 与 Pool 均满足该接口
+type StatsProvider interface {
+	Stats() connection_pool.Stats
+}
+
+// Collector 连接池的 Prometheus 采集器，每次采集时读取 Stats 快照，
+// 指标带有 pool 标签区分不同连接池
+type Collector struct {
+	pool StatsProvider
+
+	totalConnections    *prometheus.Desc
+	idleConnections     *prometheus.Desc
+	inUseConnections    *prometheus.Desc
+	creatingConnections *prometheus.Desc
+	acquireWaitSeconds  *prometheus.Desc
+	timeouts            *prometheus.Desc
+	healthCheckFailures *prometheus.Desc
+	idleClosed          *prometheus.Desc
+	refills             *prometheus.Desc
+	leaks               *prometheus.Desc
+}
+
+// NewCollector 为名为 name 的连接池创建采集器
+func NewCollector(name string, pool StatsProvider) *Collector {
+	labels := prometheus.Labels{"pool": name}
+	desc := func(metric, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "", metric), help, nil, labels)
+	}
+
+	return &Collector{
+		pool:                pool,
+		totalConnections:    desc("total_connections", "Number of connections in the pool."),
+		idleConnections:     desc("idle_connections", "Number of idle connections."),
+		inUseConnections:    desc("in_use_connections", "Number of connections currently borrowed."),
+		creatingConnections: desc("creating_connections", "Number of connections being created."),
+		acquireWaitSeconds:  desc("acquire_wait_seconds", "Time spent waiting for a connection."),
+		timeouts:            desc("acquire_timeouts_total", "Number of acquires that timed out."),
+		healthCheckFailures: desc("health_check_failures_total", "Number of connections that failed a health check."),
+		idleClosed:          desc("idle_closed_total", "Number of connections closed for exceeding the idle time."),
+		refills:             desc("refills_total", "Number of connections created to refill the pool."),
+		leaks:               desc("leaks_total", "Number of connections held longer than the leak threshold."),
+	}
+}
+
+// Register 为名为 name 的连接池创建采集器并注册到 reg
+func Register(reg prometheus.Registerer, name string, pool StatsProvider) error {
+	return reg.Register(NewCollector(name, pool))
+}
+
+// Describe 实现 prometheus.Collector 接口
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.totalConnections
+	ch <- c.idleConnections
+	ch <- c.inUseConnections
+	ch <- c.creatingConnections
+	ch <- c.acquireWaitSeconds
+	ch <- c.timeouts
+	ch <- c.healthCheckFailures
+	ch <- c.idleClosed
+	ch <- c.refills
+	ch <- c.leaks
+}
+
+// Collect 实现 prometheus.Collector 接口
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.pool.Stats()
+
+	ch <- prometheus.MustNewConstMetric(c.totalConnections, prometheus.GaugeValue, float64(stats.TotalConnections))
+	ch <- prometheus.MustNewConstMetric(c.idleConnections, prometheus.GaugeValue, float64(stats.IdleConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUseConnections, prometheus.GaugeValue, float64(stats.InUseConnections))
+	ch <- prometheus.MustNewConstMetric(c.creatingConnections, prometheus.GaugeValue, float64(stats.CreatingConnections))
+
+	buckets := make(map[float64]uint64, len(stats.WaitHistogram))
+	for _, bucket := range stats.WaitHistogram {
+		buckets[bucket.UpperBound.Seconds()] = uint64(bucket.Count)
+	}
+	ch <- prometheus.MustNewConstHistogram(c.acquireWaitSeconds, uint64(stats.AcquireCount), stats.WaitDuration.Seconds(), buckets)
+
+	ch <- prometheus.MustNewConstMetric(c.timeouts, prometheus.CounterValue, float64(stats.Timeouts))
+	ch <- prometheus.MustNewConstMetric(c.healthCheckFailures, prometheus.CounterValue, float64(stats.HealthCheckFailures))
+	ch <- prometheus.MustNewConstMetric(c.idleClosed, prometheus.CounterValue, float64(stats.IdleClosed))
+	ch <- prometheus.MustNewConstMetric(c.refills, prometheus.CounterValue, float64(stats.Refills))
+	ch <- prometheus.MustNewConstMetric(c.leaks, prometheus.CounterValue, float64(stats.Leaks))
+}
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"github.com/practice/connection-pool/pkg/pool/connection_pool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakePool struct {
+	stats connection_pool.Stats
+}
+
+func (f *fakePool) Stats() connection_pool.Stats {
+	return f.stats
+}
+
+func TestCollector(t *testing.T) {
+	pool := &fakePool{stats: connection_pool.Stats{
+		TotalConnections:    3,
+		IdleConnections:     1,
+		InUseConnections:    2,
+		AcquireCount:        4,
+		WaitDuration:        1500 * time.Millisecond,
+		WaitHistogram:       []connection_pool.WaitBucket{{UpperBound: 10 * time.Millisecond, Count: 2}, {UpperBound: time.Second, Count: 3}},
+		Timeouts:            1,
+		HealthCheckFailures: 2,
+		IdleClosed:          3,
+		Refills:             5,
+		Leaks:               6,
+	}}
+
+	reg := prometheus.NewPedanticRegistry()
+	if err := Register(reg, "mysql", pool); err != nil {
+		t.Fatal(err)
+	}
+	// 同一注册表可注册多个不同名称的连接池
+	if err := Register(reg, "redis", &fakePool{}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `
+# HELP connection_pool_acquire_timeouts_total Number of acquires that timed out.
+# TYPE connection_pool_acquire_timeouts_total counter
+connection_pool_acquire_timeouts_total{pool="mysql"} 1
+connection_pool_acquire_timeouts_total{pool="redis"} 0
+# HELP connection_pool_acquire_wait_seconds Time spent waiting for a connection.
+# TYPE connection_pool_acquire_wait_seconds histogram
+connection_pool_acquire_wait_seconds_bucket{pool="mysql",le="0.01"} 2
+connection_pool_acquire_wait_seconds_bucket{pool="mysql",le="1"} 3
+connection_pool_acquire_wait_seconds_bucket{pool="mysql",le="+Inf"} 4
+connection_pool_acquire_wait_seconds_sum{pool="mysql"} 1.5
+connection_pool_acquire_wait_seconds_count{pool="mysql"} 4
+connection_pool_acquire_wait_seconds_bucket{pool="redis",le="+Inf"} 0
+connection_pool_acquire_wait_seconds_sum{pool="redis"} 0
+connection_pool_acquire_wait_seconds_count{pool="redis"} 0
+# HELP connection_pool_health_check_failures_total Number of connections that failed a health check.
+# TYPE connection_pool_health_check_failures_total counter
+connection_pool_health_check_failures_total{pool="mysql"} 2
+connection_pool_health_check_failures_total{pool="redis"} 0
+# HELP connection_pool_idle_closed_total Number of connections closed for exceeding the idle time.
+# TYPE connection_pool_idle_closed_total counter
+connection_pool_idle_closed_total{pool="mysql"} 3
+connection_pool_idle_closed_total{pool="redis"} 0
+# HELP connection_pool_idle_connections Number of idle connections.
+# TYPE connection_pool_idle_connections gauge
+connection_pool_idle_connections{pool="mysql"} 1
+connection_pool_idle_connections{pool="redis"} 0
+# HELP connection_pool_in_use_connections Number of connections currently borrowed.
+# TYPE connection_pool_in_use_connections gauge
+connection_pool_in_use_connections{pool="mysql"} 2
+connection_pool_in_use_connections{pool="redis"} 0
+# HELP connection_pool_leaks_total Number of connections held longer than the leak threshold.
+# TYPE connection_pool_leaks_total counter
+connection_pool_leaks_total{pool="mysql"} 6
+connection_pool_leaks_total{pool="redis"} 0
+# HELP connection_pool_refills_total Number of connections created to refill the pool.
+# TYPE connection_pool_refills_total counter
+connection_pool_refills_total{pool="mysql"} 5
+connection_pool_refills_total{pool="redis"} 0
+# HELP connection_pool_total_connections Number of connections in the pool.
+# TYPE connection_pool_total_connections gauge
+connection_pool_total_connections{pool="mysql"} 3
+connection_pool_total_connections{pool="redis"} 0
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"connection_pool_acquire_timeouts_total",
+		"connection_pool_acquire_wait_seconds",
+		"connection_pool_health_check_failures_total",
+		"connection_pool_idle_closed_total",
+		"connection_pool_idle_connections",
+		"connection_pool_in_use_connections",
+		"connection_pool_leaks_total",
+		"connection_pool_refills_total",
+		"connection_pool_total_connections",
+	); err != nil {
+		t.Fatal(err)
+	}
+}
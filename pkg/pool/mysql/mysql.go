@@ -1,165 +1,53 @@
 package mysql
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	_ "github.com/go-sql-driver/mysql"
-	"github.com/practice/connection-pool/pkg/pool/config"
-	"sync"
-	"time"
+	"github.com/practice/connection-pool/pkg/pool/errs"
 )
 
-// MySQLConnectionPool 实现 ConnectionPool 接口，用于 MySQL 连接池
-type MySQLConnectionPool struct {
-	// pool 存放连接池chan
-	pool chan *sql.DB
-	// config 连接池通用配置
-	config *config.ConnectionConfig
-	// mysqlOpts mysql私有配置，不对外暴露
-	mysqlOpts *mysqlOpt
-	// connectionNum 记录当下池中的连接数
-	connectionNum int
-	// lastAccessed 记录每个连接实例的最后使用时间
-	lastAccessed map[*sql.DB]time.Time
-	mu           sync.Mutex
+// MySQLFactory mysql连接工厂，实现 connection_pool.Factory 接口，
+// 创建的每个 *sql.Conn 独占一个物理连接
+type MySQLFactory struct {
+	// db 用于创建独占的物理连接
+	db *sql.DB
 }
 
-type mysqlOpt struct {
-	driver string
-	dsn    string
-}
-
-// NewMySQLConnectionPool 创建 MySQL 连接池
-func NewMySQLConnectionPool(driver, dsn string, cfg *config.ConnectionConfig) (*MySQLConnectionPool, error) {
+// NewMySQLFactory 创建 MySQL 连接工厂
+func NewMySQLFactory(driver, dsn string) (*MySQLFactory, error) {
 	db, err := sql.Open(driver, dsn)
 	if err != nil {
 		return nil, err
 	}
+	// 由连接池限制物理连接数量，*sql.Conn 关闭后直接断开，不放回 *sql.DB 的空闲池
+	db.SetMaxIdleConns(0)
 
-	pool := make(chan *sql.DB, cfg.MaxConnections)
-	for i := 0; i < cfg.MaxConnections; i++ {
-		conn := db
-		pool <- conn
-	}
-
-	p := &MySQLConnectionPool{
-		pool:         pool,
-		config:       cfg,
-		mysqlOpts:    &mysqlOpt{driver: driver, dsn: dsn},
-		lastAccessed: make(map[*sql.DB]time.Time),
-	}
-
-	// 启动定时任务
-	go p.startCleanupTask(cfg.CleanupInterval)
-	go p.startCheckAndModifyConnectionNum()
-	go p.startHealthCheckTask()
-
-	return p, nil
+	return &MySQLFactory{
+		db: db,
+	}, nil
 }
 
-// GetConnection 从 MySQL 连接池获取连接
-func (p *MySQLConnectionPool) GetConnection() (interface{}, error) {
-	select {
-	case conn := <-p.pool:
-		p.mu.Lock()
-		p.lastAccessed[conn] = time.Now()
-		p.mu.Unlock()
-		return conn, nil
-	case <-time.After(p.config.Timeout):
-		return nil, fmt.Errorf("timeout: failed to get MySQL connection")
-	}
+// Dial 从 *sql.DB 中取出一个独占的物理连接
+func (f *MySQLFactory) Dial(ctx context.Context) (*sql.Conn, error) {
+	return f.db.Conn(ctx)
 }
 
-// ReleaseConnection 释放 MySQL 连接到连接池
-func (p *MySQLConnectionPool) ReleaseConnection(conn interface{}) {
-	p.pool <- conn.(*sql.DB)
-}
-
-// ReclaimConnections 回收空闲连接
-
-func (p *MySQLConnectionPool) reclaimConnections() {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-
-	now := time.Now()
-	for conn, lastAccessed := range p.lastAccessed {
-		if now.Sub(lastAccessed) > p.config.MaxIdleTime {
-			conn.Close()
-			delete(p.lastAccessed, conn)
-		}
-	}
-}
-
-// startCleanupTask 启动定时任务来定期调用回收空闲连接的方法
-func (p *MySQLConnectionPool) startCleanupTask(interval time.Duration) {
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		p.reclaimConnections()
+// Validate 检查连接是否可用，失败时返回包装了 errs.ErrConnectionUnhealthy 的错误
+func (f *MySQLFactory) Validate(ctx context.Context, conn *sql.Conn) error {
+	if err := conn.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping MySQL connection: %w: %w", errs.ErrConnectionUnhealthy, err)
 	}
+	return nil
 }
 
-// Close 关闭 MySQL 连接池
-func (p *MySQLConnectionPool) Close() {
-	close(p.pool)
+// Close 关闭 MySQL 连接，对应的物理连接随之断开
+func (f *MySQLFactory) Close(conn *sql.Conn) error {
+	return conn.Close()
 }
 
-// startHealthCheckTask 启动定时任务来定期进行连接池的健康检查
-func (p *MySQLConnectionPool) startHealthCheckTask() {
-	ticker := time.NewTicker(p.config.HealthCheckInterval)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		p.checkConnectionsHealth()
-	}
-}
-
-// checkConnectionsHealth 检查连接池中连接的健康状态
-func (p *MySQLConnectionPool) checkConnectionsHealth() {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-
-	now := time.Now()
-	for conn := range p.lastAccessed {
-		// 健康检查逻辑
-		if err := conn.Ping(); err != nil {
-			// 连接无效，关闭连接并从连接池中移除
-			conn.Close()
-			delete(p.lastAccessed, conn)
-		} else if now.Sub(p.lastAccessed[conn]) > p.config.MaxIdleTime {
-			// 连接超时，关闭连接并从连接池中移除
-			conn.Close()
-			delete(p.lastAccessed, conn)
-		}
-	}
-}
-
-// startCheckAndModifyConnectionNum 启动定时任务来定期进行连接池的连接数检查
-func (p *MySQLConnectionPool) startCheckAndModifyConnectionNum() {
-	ticker := time.NewTicker(p.config.HealthCheckInterval)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		p.checkAndModifyConnectionNum()
-	}
-}
-
-// checkAndModifyConnectionNum 检查连接池中连接数量，不够则创建
-func (p *MySQLConnectionPool) checkAndModifyConnectionNum() {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-
-	if (p.config.MaxConnections - p.connectionNum) <= 0 {
-		return
-	}
-	newConnectionNum := p.config.MaxConnections - p.connectionNum
-	db, err := sql.Open(p.mysqlOpts.driver, p.mysqlOpts.dsn)
-	if err != nil {
-		return
-	}
-	for i := 0; i < newConnectionNum; i++ {
-		conn := db
-		p.pool <- conn
-	}
+// Shutdown 关闭底层 *sql.DB
+func (f *MySQLFactory) Shutdown() error {
+	return f.db.Close()
 }
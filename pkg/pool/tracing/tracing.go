@@ -0,0 +1,78 @@
+package tracing
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"time"
+)
+
+const instrumentationName = "github.com/practice/connection-pool/pkg/pool/tracing"
+
+// Tracer 基于 OpenTelemetry 的链路追踪，实现 config.Tracer 接口，
+// 获取连接、归还连接与每轮健康检查各生成一个 span
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+// NewTracer 使用 provider 创建 Tracer，provider 为 nil 时使用全局 TracerProvider
+func NewTracer(provider trace.TracerProvider) *Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return &Tracer{
+		tracer: provider.Tracer(instrumentationName),
+	}
+}
+
+// StartAcquire 创建获取连接的 span，父 span 取自调用方的 ctx
+func (t *Tracer) StartAcquire(ctx context.Context, pool string) (context.Context, func(wait time.Duration, err error)) {
+	ctx, span := t.tracer.Start(ctx, "connection_pool.acquire",
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attribute.String("pool.name", pool)),
+	)
+	return ctx, func(wait time.Duration, err error) {
+		span.SetAttributes(attribute.Float64("pool.wait_duration_ms", milliseconds(wait)))
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// Release 创建归还连接的 span，作为对应获取 span 的子 span
+func (t *Tracer) Release(ctx context.Context, pool string, borrowed time.Duration) {
+	_, span := t.tracer.Start(ctx, "connection_pool.release",
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			attribute.String("pool.name", pool),
+			attribute.Float64("pool.borrow_duration_ms", milliseconds(borrowed)),
+		),
+	)
+	span.End()
+}
+
+// StartHealthCheck 创建一轮健康检查的 span
+func (t *Tracer) StartHealthCheck(ctx context.Context, pool string) (context.Context, func(checked, failed int)) {
+	ctx, span := t.tracer.Start(ctx, "connection_pool.health_check",
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(attribute.String("pool.name", pool)),
+	)
+	return ctx, func(checked, failed int) {
+		span.SetAttributes(
+			attribute.Int("pool.health_check.checked", checked),
+			attribute.Int("pool.health_check.failed", failed),
+		)
+		if failed > 0 {
+			span.SetStatus(codes.Error, "unhealthy connections removed")
+		}
+		span.End()
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,102 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"github.com/practice/connection-pool/pkg/pool/config"
+	"github.com/practice/connection-pool/pkg/pool/connection_pool"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+	"time"
+)
+
+type conn struct{}
+
+// factory 测试用连接工厂
+type factory struct{}
+
+func (factory) Dial(ctx context.Context) (*conn, error)     { return &conn{}, nil }
+func (factory) Validate(ctx context.Context, c *conn) error { return nil }
+func (factory) Close(c *conn) error                         { return nil }
+
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) (attribute.Value, bool) {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestTracer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	p, err := connection_pool.NewGenericConnectionPool[*conn]("fake", factory{}, &config.ConnectionConfig{
+		MaxConnections:      1,
+		Timeout:             20 * time.Millisecond,
+		MaxIdleTime:         time.Minute,
+		HealthCheckInterval: 10 * time.Millisecond,
+		CleanupInterval:     time.Minute,
+		Tracer:              NewTracer(provider),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close(context.Background())
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+	c, err := p.GetConnection(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.GetConnection(ctx); !errors.Is(err, connection_pool.ErrAcquireTimeout) {
+		t.Fatalf("expected ErrAcquireTimeout, got %v", err)
+	}
+	p.ReleaseConnection(c)
+	parent.End()
+	time.Sleep(50 * time.Millisecond)
+
+	var acquires, releases, healthChecks []sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		switch span.Name() {
+		case "connection_pool.acquire":
+			acquires = append(acquires, span)
+		case "connection_pool.release":
+			releases = append(releases, span)
+		case "connection_pool.health_check":
+			healthChecks = append(healthChecks, span)
+		}
+	}
+	if len(acquires) != 2 || len(releases) != 1 || len(healthChecks) == 0 {
+		t.Fatalf("unexpected spans: %d acquire, %d release, %d health check", len(acquires), len(releases), len(healthChecks))
+	}
+
+	// 获取 span 挂在调用方的 span 下，归还 span 挂在对应的获取 span 下
+	for _, span := range acquires {
+		if span.Parent().SpanID() != parent.SpanContext().SpanID() {
+			t.Fatal("acquire span is not parented to the caller's span")
+		}
+		if v, _ := spanAttr(span, "pool.name"); v.AsString() != "fake" {
+			t.Fatalf("unexpected pool.name %q", v.AsString())
+		}
+	}
+	if releases[0].Parent().SpanID() != acquires[0].SpanContext().SpanID() {
+		t.Fatal("release span is not parented to the acquire span")
+	}
+	if _, ok := spanAttr(releases[0], "pool.borrow_duration_ms"); !ok {
+		t.Fatal("release span has no borrow duration")
+	}
+	if v, _ := spanAttr(acquires[1], "pool.wait_duration_ms"); v.AsFloat64() < 20 {
+		t.Fatalf("expected wait duration of at least 20ms, got %v", v.AsFloat64())
+	}
+	if len(acquires[1].Events()) == 0 {
+		t.Fatal("acquire timeout was not recorded on the span")
+	}
+	// 健康检查只检查空闲连接，归还后的一轮检查到该连接
+	if v, _ := spanAttr(healthChecks[len(healthChecks)-1], "pool.health_check.checked"); v.AsInt64() != 1 {
+		t.Fatalf("unexpected checked count %d", v.AsInt64())
+	}
+}